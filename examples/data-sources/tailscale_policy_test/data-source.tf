@@ -0,0 +1,16 @@
+data "tailscale_policy_test" "sample_policy_test" {
+  policy = file("${path.module}/policy.hujson")
+
+  test {
+    src    = "alice@example.com"
+    accept = ["tag:web:443"]
+    deny   = ["tag:db:5432"]
+  }
+}
+
+check "policy_tests" {
+  assert {
+    condition     = data.tailscale_policy_test.sample_policy_test.passed
+    error_message = "Policy file tests failed: ${jsonencode([for r in data.tailscale_policy_test.sample_policy_test.results : r.failures if !r.passed])}"
+  }
+}
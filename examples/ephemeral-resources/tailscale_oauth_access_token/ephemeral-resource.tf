@@ -0,0 +1,17 @@
+variable "oauth_client_secret" {
+  type      = string
+  sensitive = true
+}
+
+ephemeral "tailscale_oauth_access_token" "devices" {
+  client_id     = "k1234567CNTRL"
+  client_secret = var.oauth_client_secret
+  scopes        = ["devices:core:read"]
+}
+
+# The access token can be used in place of an API key, and is never stored in
+# the state or the plan.
+provider "tailscale" {
+  alias   = "devices"
+  api_key = ephemeral.tailscale_oauth_access_token.devices.access_token
+}
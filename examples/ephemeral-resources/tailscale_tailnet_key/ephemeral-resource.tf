@@ -0,0 +1,13 @@
+ephemeral "tailscale_tailnet_key" "ci" {
+  reusable      = false
+  ephemeral     = true
+  preauthorized = true
+  tags          = ["tag:ci"]
+  description   = "CI runner"
+}
+
+# The key is only valid during the Terraform run, and is never stored in the
+# state or the plan.
+provider "example" {
+  tailscale_auth_key = ephemeral.tailscale_tailnet_key.ci.key
+}
@@ -0,0 +1,4 @@
+# 100.64.0.0/10
+output "tailscale_ipv4_range" {
+  value = provider::tailscale::cgnat_range()
+}
@@ -0,0 +1,4 @@
+# true
+output "is_tailscale_ip" {
+  value = provider::tailscale::is_tailscale_ip("100.101.102.103")
+}
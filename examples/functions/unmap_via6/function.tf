@@ -0,0 +1,4 @@
+# { site = 7, cidr = "10.1.1.0/24" }
+output "site_7_subnet" {
+  value = provider::tailscale::unmap_via6("fd7a:115c:a1e0:b1a:0:7:a01:100/120")
+}
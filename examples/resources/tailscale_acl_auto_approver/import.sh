@@ -0,0 +1,4 @@
+# Route auto approvers can be imported using the route, e.g.,
+terraform import tailscale_acl_auto_approver.office_route 10.0.0.0/24
+# Exit node auto approvers can be imported using `exitNode`, e.g.,
+terraform import tailscale_acl_auto_approver.exit_node exitNode
@@ -0,0 +1,8 @@
+resource "tailscale_acl_auto_approver" "office_route" {
+  route     = "10.0.0.0/24"
+  approvers = ["tag:router"]
+}
+
+resource "tailscale_acl_auto_approver" "exit_node" {
+  approvers = ["tag:exit-node"]
+}
@@ -0,0 +1,2 @@
+# Grants can be imported using their index in the `grants` section of the policy file, e.g.,
+terraform import tailscale_acl_grant.web 0
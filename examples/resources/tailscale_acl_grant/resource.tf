@@ -0,0 +1,5 @@
+resource "tailscale_acl_grant" "web" {
+  src = ["group:engineering"]
+  dst = ["tag:web"]
+  ip  = ["tcp:443"]
+}
@@ -0,0 +1,2 @@
+# Groups can be imported using the group name, e.g.,
+terraform import tailscale_acl_group.engineering group:engineering
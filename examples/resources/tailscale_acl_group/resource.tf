@@ -0,0 +1,4 @@
+resource "tailscale_acl_group" "engineering" {
+  name    = "group:engineering"
+  members = ["alice@example.com", "bob@example.com"]
+}
@@ -0,0 +1,2 @@
+# Hosts can be imported using the host name, e.g.,
+terraform import tailscale_acl_host.database database
@@ -0,0 +1,4 @@
+resource "tailscale_acl_host" "database" {
+  name    = "database"
+  address = "100.100.100.100"
+}
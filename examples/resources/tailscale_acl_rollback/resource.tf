@@ -0,0 +1,13 @@
+resource "tailscale_acl" "sample_acl" {
+  acl               = file("${path.module}/policy.hujson")
+  keep_previous_acl = true
+}
+
+# Add this resource and run
+#   terraform apply -target=tailscale_acl_rollback.revert
+# to restore the policy file as it was before the last apply of
+# tailscale_acl.sample_acl, then remove it again.
+resource "tailscale_acl_rollback" "revert" {
+  acl  = tailscale_acl.sample_acl.previous_acl
+  etag = tailscale_acl.sample_acl.etag
+}
@@ -0,0 +1,2 @@
+# SSH access rules can be imported using their index in the `ssh` section of the policy file, e.g.,
+terraform import tailscale_acl_ssh.self 0
@@ -0,0 +1,6 @@
+resource "tailscale_acl_ssh" "self" {
+  action = "check"
+  src    = ["autogroup:member"]
+  dst    = ["autogroup:self"]
+  users  = ["autogroup:nonroot", "root"]
+}
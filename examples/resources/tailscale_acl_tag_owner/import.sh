@@ -0,0 +1,2 @@
+# Tag owners can be imported using the tag, e.g.,
+terraform import tailscale_acl_tag_owner.web tag:web
@@ -0,0 +1,4 @@
+resource "tailscale_acl_tag_owner" "web" {
+  tag    = "tag:web"
+  owners = ["group:engineering"]
+}
@@ -0,0 +1,2 @@
+# ID doesn't matter.
+terraform import tailscale_policy.sample_policy policy
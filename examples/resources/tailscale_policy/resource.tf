@@ -0,0 +1,43 @@
+resource "tailscale_policy" "sample_policy" {
+  grants {
+    src = ["group:engineering"]
+    dst = ["tag:server"]
+    ip  = ["tcp:22", "tcp:443"]
+  }
+
+  groups {
+    name    = "group:engineering"
+    members = ["alice@example.com", "bob@example.com"]
+  }
+
+  tag_owners {
+    tag    = "tag:server"
+    owners = ["group:engineering"]
+  }
+
+  hosts = {
+    example-host = "100.100.100.100"
+  }
+
+  auto_approvers {
+    routes {
+      cidr      = "10.0.0.0/24"
+      approvers = ["tag:server"]
+    }
+    exit_node = ["tag:server"]
+  }
+
+  ssh {
+    action       = "check"
+    src          = ["group:engineering"]
+    dst          = ["tag:server"]
+    users        = ["autogroup:nonroot"]
+    check_period = "12h"
+  }
+
+  tests {
+    src    = "alice@example.com"
+    accept = ["tag:server:22"]
+    deny   = ["example-host:22"]
+  }
+}
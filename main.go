@@ -5,16 +5,25 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 
 	"github.com/tailscale/terraform-provider-tailscale/tailscale"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return tailscale.Provider()
-		},
+	server, err := tailscale.ProviderServer(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = tf5server.Serve("registry.terraform.io/tailscale/tailscale", func() tfprotov5.ProviderServer {
+		return server
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,158 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultCredentialsProfile is the profile used when 'profile' is not set.
+const defaultCredentialsProfile = "default"
+
+// credentialsProfileArguments are the provider arguments that can be set by a
+// credentials profile, with the environment variables that set them.
+var credentialsProfileArguments = map[string][]string{
+	"api_key":             {"TAILSCALE_API_KEY"},
+	"oauth_client_id":     oauthClientIDEnvVars,
+	"oauth_client_secret": oauthClientSecretEnvVars,
+	"tailnet":             {"TAILSCALE_TAILNET"},
+	"base_url":            {"TAILSCALE_BASE_URL"},
+}
+
+// defaultCredentialsFile returns the path of the credentials file used when
+// 'config_file' is not set.
+func defaultCredentialsFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "tailscale", "credentials"), nil
+}
+
+// loadCredentialsProfile returns the arguments set by the selected profile of
+// the credentials file. It returns no arguments if neither 'profile' nor
+// 'config_file' is set and the default credentials file or profile does not
+// exist.
+func loadCredentialsProfile(d *schema.ResourceData) (map[string]string, error) {
+	profile := d.Get("profile").(string)
+	path := d.Get("config_file").(string)
+	explicit := profile != "" || path != ""
+
+	if profile == "" {
+		profile = defaultCredentialsProfile
+	}
+	if path == "" {
+		var err error
+		if path, err = defaultCredentialsFile(); err != nil {
+			if explicit {
+				return nil, fmt.Errorf("failed to find credentials file: %w", err)
+			}
+			return nil, nil
+		}
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open credentials file: %w", err)
+	}
+	defer f.Close()
+
+	profiles, err := parseCredentialsFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %q: %w", path, err)
+	}
+	values, ok := profiles[profile]
+	if !ok {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile %q not found in credentials file %q", profile, path)
+	}
+	return values, nil
+}
+
+// parseCredentialsFile parses a credentials file in the INI format used by
+// AWS shared credentials files, for example:
+//
+//	[default]
+//	api_key = tskey-api-...
+//
+//	[staging]
+//	oauth_client_id     = ...
+//	oauth_client_secret = tskey-client-...
+//	tailnet             = staging.example.com
+//
+// Lines starting with `#` or `;` are comments.
+func parseCredentialsFile(r io.Reader) (map[string]map[string]string, error) {
+	profiles := make(map[string]map[string]string)
+	var current map[string]string
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";"):
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			// Like AWS config files, allow `[profile name]`.
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty profile name", line)
+			}
+			if current = profiles[name]; current == nil {
+				current = make(map[string]string)
+				profiles[name] = current
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: %q is not in a profile", line, strings.TrimSpace(key))
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := credentialsProfileArguments[key]; !ok {
+			return nil, fmt.Errorf("line %d: unknown key %q", line, key)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		current[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// isArgumentSet reports whether a provider argument is set in the
+// configuration or through one of its environment variables.
+func isArgumentSet(d *schema.ResourceData, key string) bool {
+	if raw := d.GetRawConfig(); !raw.IsNull() && raw.IsKnown() && raw.Type().HasAttribute(key) && !raw.GetAttr(key).IsNull() {
+		return true
+	}
+	for _, env := range credentialsProfileArguments[key] {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -15,7 +15,7 @@ import (
 
 func dataSource4Via6() *schema.Resource {
 	return &schema.Resource{
-		Description: "The 4via6 data source is calculates an IPv6 prefix for a given site ID and IPv4 CIDR. See Tailscale documentation for [4via6 subnets](https://tailscale.com/kb/1201/4via6-subnets/) for more details.",
+		Description: "The 4via6 data source is calculates an IPv6 prefix for a given site ID and IPv4 CIDR. The `provider::tailscale::via6` function calculates the same prefix without a data source, and requires Terraform 1.8 or later. See Tailscale documentation for [4via6 subnets](https://tailscale.com/kb/1201/4via6-subnets/) for more details.",
 		ReadContext: dataSource4Via6Read,
 		Schema: map[string]*schema.Schema{
 			"site": {
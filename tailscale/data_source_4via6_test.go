@@ -31,8 +31,8 @@ data "tailscale_4via6" "invalid" {
 
 func TestProvider_DataSourceTailscale4Via6(t *testing.T) {
 	resource.ParallelTest(t, resource.TestCase{
-		IsUnitTest:        true,
-		ProviderFactories: testProviderFactories(t),
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: testDataSource4Via6,
@@ -44,8 +44,8 @@ func TestProvider_DataSourceTailscale4Via6(t *testing.T) {
 
 func TestProvider_DataSourceTailscale4Via6_InvalidSite(t *testing.T) {
 	resource.ParallelTest(t, resource.TestCase{
-		IsUnitTest:        true,
-		ProviderFactories: testProviderFactories(t),
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config:      testDataSource4Via6InvalidSite,
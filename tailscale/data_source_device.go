@@ -168,7 +168,7 @@ func dataSourceDeviceRead(ctx context.Context, d *schema.ResourceData, m interfa
 		filterDesc = fmt.Sprintf("hostname=%q", hostname.(string))
 	}
 
-	devices, err := client.Devices().List(ctx)
+	devices, err := listDevices(ctx, client)
 	if err != nil {
 		return diagnosticsError(err, "Failed to fetch devices")
 	}
@@ -182,6 +182,8 @@ func dataSourceDeviceRead(ctx context.Context, d *schema.ResourceData, m interfa
 	}
 
 	if selected == nil {
+		// The device may have been added since the device cache was filled.
+		invalidateDevices(client)
 		return diag.Errorf("Could not find device with %s", filterDesc)
 	}
 
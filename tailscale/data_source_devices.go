@@ -190,7 +190,14 @@ func dataSourceDevicesRead(ctx context.Context, d *schema.ResourceData, m interf
 		}
 	}
 
-	devices, err := client.Devices().List(ctx, opts...)
+	var devices []tailscale.Device
+	var err error
+	if len(opts) == 0 {
+		devices, err = listDevices(ctx, client)
+	} else {
+		// Filters are applied by the API, so the device cache cannot be used.
+		devices, err = client.Devices().List(ctx, opts...)
+	}
 	if err != nil {
 		return diagnosticsError(err, "Failed to fetch devices")
 	}
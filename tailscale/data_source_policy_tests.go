@@ -0,0 +1,183 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+const dataSourcePolicyTestDescription = `The policy_test data source evaluates policy file tests locally, without calling the Tailscale API. See https://tailscale.com/kb/1337/policy-syntax#tests for more information on tests.
+
+Tests are evaluated against the acls and grants of the given policy file by a matcher built into the provider, which supports users, groups, tags, host aliases, IP addresses, CIDRs, ` + "`*` and `autogroup:member`" + `. Rules using other autogroups or ipsets are not evaluated, so the results may differ from the Tailscale API for policy files using them. Device postures and via routing are not evaluated either, and tests of connections that are only allowed by rules using them fail with an error.`
+
+func dataSourcePolicyTest() *schema.Resource {
+	return &schema.Resource{
+		Description: dataSourcePolicyTestDescription,
+		ReadContext: dataSourcePolicyTestRead,
+		Schema: map[string]*schema.Schema{
+			"policy": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "The policy file to evaluate, as a JSON or HuJSON string.",
+				ValidateDiagFunc: validatePolicyHuJSON,
+			},
+			"include_policy_tests": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to evaluate the tests defined in the `tests` section of the policy file, in addition to the configured `test` blocks. Defaults to `true`.",
+			},
+			"test": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Additional tests to evaluate.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"src": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The user, tag, host alias or IP address the connections originate from.",
+						},
+						"accept": policyStringList("The destinations (`host:port`) that `src` must be able to connect to.", false),
+						"deny":   policyStringList("The destinations (`host:port`) that `src` must not be able to connect to.", false),
+					},
+				},
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The results of the tests, starting with the tests defined in the policy file.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"src": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The source of the test.",
+						},
+						"accept": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The destinations that `src` must be able to connect to.",
+						},
+						"deny": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The destinations that `src` must not be able to connect to.",
+						},
+						"from_policy": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the test is defined in the policy file.",
+						},
+						"passed": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the test passed.",
+						},
+						"failures": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A description of each assertion of the test that failed.",
+						},
+					},
+				},
+			},
+			"passed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether all tests passed.",
+			},
+		},
+	}
+}
+
+func dataSourcePolicyTestRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy := d.Get("policy").(string)
+
+	evaluator, err := newPolicyEvaluator(policy)
+	if err != nil {
+		return diagnosticsError(err, "Failed to parse policy file")
+	}
+
+	var tests []tailscale.ACLTest
+	if d.Get("include_policy_tests").(bool) {
+		tests = evaluator.tests()
+	}
+	policyTests := len(tests)
+	for _, item := range d.Get("test").([]interface{}) {
+		test := blockMap(item)
+		tests = append(tests, tailscale.ACLTest{
+			Source: test["src"].(string),
+			Accept: expandStrings(test["accept"]),
+			Deny:   expandStrings(test["deny"]),
+		})
+	}
+
+	passed := true
+	results := make([]map[string]any, 0, len(tests))
+	for i, test := range tests {
+		// Older policy files use `user` and `allow` instead of `src` and `accept`.
+		src := test.Source
+		if src == "" {
+			src = test.User
+		}
+		accept := append(append([]string{}, test.Accept...), test.Allow...)
+
+		failures, err := evaluatePolicyTest(evaluator, src, accept, test.Deny)
+		if err != nil {
+			return diagnosticsError(err, "Failed to evaluate test %d", i)
+		}
+		passed = passed && len(failures) == 0
+
+		results = append(results, map[string]any{
+			"src":         src,
+			"accept":      accept,
+			"deny":        test.Deny,
+			"from_policy": i < policyTests,
+			"passed":      len(failures) == 0,
+			"failures":    failures,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256([]byte(policy))))
+	return setProperties(d, map[string]any{
+		"results": results,
+		"passed":  passed,
+	})
+}
+
+// evaluatePolicyTest returns a description of each assertion of a test that
+// does not hold.
+func evaluatePolicyTest(evaluator *policyEvaluator, src string, accept, deny []string) ([]string, error) {
+	var failures []string
+	for _, dst := range accept {
+		allowed, err := evaluator.allowed(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			failures = append(failures, fmt.Sprintf("%s cannot connect to %s, but is expected to", src, dst))
+		}
+	}
+	for _, dst := range deny {
+		allowed, err := evaluator.allowed(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			failures = append(failures, fmt.Sprintf("%s can connect to %s, but is expected not to", src, dst))
+		}
+	}
+	return failures, nil
+}
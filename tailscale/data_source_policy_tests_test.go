@@ -0,0 +1,155 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testDataSourcePolicyTest = `
+data "tailscale_policy_test" "example" {
+  policy = <<EOF
+  {
+    "groups": {"group:eng": ["alice@example.com"]},
+    "tagOwners": {"tag:web": ["group:eng"], "tag:db": ["group:eng"]},
+    "hosts": {"office": "10.0.0.0/24"},
+    "acls": [{"action": "accept", "src": ["group:eng"], "dst": ["tag:db:5432"]}],
+    "grants": [
+      {"src": ["autogroup:member"], "dst": ["tag:web"], "ip": ["tcp:443"]},
+      {"src": ["tag:web"], "dst": ["office"], "ip": ["80,8000-8080"]},
+    ],
+    "tests": [{"src": "alice@example.com", "accept": ["tag:db:5432", "tag:web:443"], "deny": ["tag:web:22"]}],
+  }
+  EOF
+
+  test {
+    src    = "bob@example.com"
+    accept = ["tag:web:443", "tag:db:5432"]
+  }
+
+  test {
+    src    = "tag:web"
+    accept = ["10.0.0.1:8080"]
+    deny   = ["10.0.1.1:8080"]
+  }
+}
+`
+
+func TestProvider_DataSourceTailscalePolicyTest(t *testing.T) {
+	const name = "data.tailscale_policy_test.example"
+
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourcePolicyTest,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "passed", "false"),
+					resource.TestCheckResourceAttr(name, "results.#", "3"),
+					resource.TestCheckResourceAttr(name, "results.0.from_policy", "true"),
+					resource.TestCheckResourceAttr(name, "results.0.passed", "true"),
+					resource.TestCheckResourceAttr(name, "results.1.from_policy", "false"),
+					resource.TestCheckResourceAttr(name, "results.1.passed", "false"),
+					resource.TestCheckResourceAttr(name, "results.1.failures.#", "1"),
+					resource.TestCheckResourceAttr(name, "results.1.failures.0", "bob@example.com cannot connect to tag:db:5432, but is expected to"),
+					resource.TestCheckResourceAttr(name, "results.2.passed", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestPolicyEvaluator(t *testing.T) {
+	t.Parallel()
+
+	evaluator, err := newPolicyEvaluator(`{
+		"Groups": {"group:ops": ["ops@example.com"]},
+		"Hosts": {"db": "100.64.0.1", "lan": "192.168.0.0/16"},
+		// Legacy syntax.
+		"ACLs": [{"Action": "accept", "Users": ["group:ops"], "Ports": ["db:22", "lan:*"]}],
+		"grants": [
+			{"src": ["*"], "dst": ["db"], "ip": ["udp:53", "icmp:*"]},
+			{"src": ["100.64.0.0/10"], "dst": ["tag:web"], "ip": ["*"]},
+		],
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		src, dst string
+		want     bool
+	}{
+		{"ops@example.com", "db:22", true},
+		{"ops@example.com", "100.64.0.1:22", true},
+		{"ops@example.com", "db:23", false},
+		{"ops@example.com", "192.168.1.1:443", true},
+		{"dev@example.com", "db:22", false},
+		{"dev@example.com", "db:53", false},
+		{"100.100.100.100", "tag:web:8080", true},
+		{"10.0.0.1", "tag:web:8080", false},
+	}
+	for _, tt := range tests {
+		got, err := evaluator.allowed(tt.src, tt.dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("allowed(%q, %q) = %v, want %v", tt.src, tt.dst, got, tt.want)
+		}
+	}
+
+	if _, err := evaluator.allowed("ops@example.com", "db:*"); err == nil {
+		t.Error("expected error for destination without a single port")
+	}
+}
+
+func TestPolicyEvaluatorConditionalRules(t *testing.T) {
+	t.Parallel()
+
+	evaluator, err := newPolicyEvaluator(`{
+		"postures": {"posture:latest": ["node:tsVersion >= '1.60'"]},
+		"acls": [
+			{"action": "accept", "src": ["ops@example.com"], "dst": ["tag:db:5432"], "srcPosture": ["posture:latest"]},
+			{"action": "accept", "src": ["*"], "dst": ["tag:db:5432"]},
+		],
+		"grants": [
+			{"src": ["dev@example.com"], "dst": ["tag:web"], "ip": ["443"], "srcPosture": ["posture:latest"]},
+			{"src": ["dev@example.com"], "dst": ["tag:web"], "ip": ["80"], "via": ["tag:exit"]},
+		],
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Connections allowed by a rule without conditions are evaluated.
+	if allowed, err := evaluator.allowed("ops@example.com", "tag:db:5432"); err != nil || !allowed {
+		t.Errorf("expected the connection to be allowed, got %v, %v", allowed, err)
+	}
+	// Connections not allowed by any rule are evaluated.
+	if allowed, err := evaluator.allowed("ops@example.com", "tag:web:443"); err != nil || allowed {
+		t.Errorf("expected the connection to be denied, got %v, %v", allowed, err)
+	}
+	// Connections only allowed by rules with a condition cannot be evaluated.
+	for _, dst := range []string{"tag:web:443", "tag:web:80"} {
+		if _, err := evaluator.allowed("dev@example.com", dst); err == nil || !strings.Contains(err.Error(), "not evaluated") {
+			t.Errorf("expected an error for %s, got %v", dst, err)
+		}
+	}
+
+	evaluator, err = newPolicyEvaluator(`{
+		"defaultSrcPosture": ["posture:latest"],
+		"grants": [{"src": ["*"], "dst": ["tag:web"], "ip": ["*"]}],
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := evaluator.allowed("dev@example.com", "tag:web:443"); err == nil {
+		t.Error("expected an error for a rule with the default device posture condition")
+	}
+}
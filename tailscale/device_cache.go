@@ -0,0 +1,131 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// deviceCache serves device reads from a single list of all devices in the
+// tailnet, so that refreshing many device resources does not make one
+// request per device. The list is fetched again once it is older than the
+// TTL. Devices that were changed by the provider are removed from the cache,
+// and read from the API until the list is fetched again.
+type deviceCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	fetchedAt time.Time
+	devices   []tailscale.Device
+	// byID indexes devices by both their legacy ID and node ID.
+	byID map[string]*tailscale.Device
+	// listStale is set once a device was changed since the list was fetched.
+	listStale bool
+}
+
+// newDeviceCache returns an empty device cache with the given TTL.
+func newDeviceCache(ttl time.Duration) *deviceCache {
+	return &deviceCache{ttl: ttl}
+}
+
+// deviceCacheFor returns the device cache of the given client, or nil if the
+// device cache is not enabled.
+func deviceCacheFor(client *tailscale.Client) *deviceCache {
+	return providerStateFor(client).deviceCache
+}
+
+// getDevice returns the device with the given legacy ID or node ID, from the
+// device cache if it is enabled.
+func getDevice(ctx context.Context, client *tailscale.Client, deviceID string) (*tailscale.Device, error) {
+	cache := deviceCacheFor(client)
+	if cache == nil {
+		return client.Devices().Get(ctx, deviceID)
+	}
+
+	cache.mu.Lock()
+	if err := cache.refresh(ctx, client, false); err != nil {
+		cache.mu.Unlock()
+		return nil, err
+	}
+	device, ok := cache.byID[deviceID]
+	cache.mu.Unlock()
+
+	if !ok {
+		// The device was changed, or added after the list was fetched.
+		return client.Devices().Get(ctx, deviceID)
+	}
+	copied := *device
+	return &copied, nil
+}
+
+// listDevices returns all devices in the tailnet, from the device cache if it
+// is enabled.
+func listDevices(ctx context.Context, client *tailscale.Client) ([]tailscale.Device, error) {
+	cache := deviceCacheFor(client)
+	if cache == nil {
+		return client.Devices().List(ctx)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if err := cache.refresh(ctx, client, cache.listStale); err != nil {
+		return nil, err
+	}
+	return append([]tailscale.Device(nil), cache.devices...), nil
+}
+
+// invalidateDevice removes the device with the given legacy ID or node ID
+// from the device cache, if it is enabled. It must be called after changing
+// a device.
+func invalidateDevice(client *tailscale.Client, deviceID string) {
+	cache := deviceCacheFor(client)
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if device, ok := cache.byID[deviceID]; ok {
+		delete(cache.byID, device.ID)
+		delete(cache.byID, device.NodeID)
+	}
+	cache.listStale = true
+}
+
+// invalidateDevices empties the device cache, if it is enabled.
+func invalidateDevices(client *tailscale.Client) {
+	if cache := deviceCacheFor(client); cache != nil {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		cache.fetchedAt = time.Time{}
+	}
+}
+
+// refresh fetches the list of devices if it is older than the TTL, or if
+// force is set. The caller must hold the lock, so that concurrent reads wait
+// for a single list request.
+func (c *deviceCache) refresh(ctx context.Context, client *tailscale.Client, force bool) error {
+	if !force && !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl {
+		return nil
+	}
+
+	devices, err := client.Devices().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.devices = devices
+	c.byID = make(map[string]*tailscale.Device, 2*len(devices))
+	for i := range devices {
+		c.byID[devices[i].ID] = &devices[i]
+		c.byID[devices[i].NodeID] = &devices[i]
+	}
+	c.fetchedAt = time.Now()
+	c.listStale = false
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+func TestDeviceCache(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	tags := `["tag:old"]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.Method+" "+r.URL.Path]++
+
+		device := `{"id": "123", "nodeId": "n123", "tags": ` + tags + `}`
+		switch {
+		case r.Method == http.MethodPost:
+			tags = `["tag:new"]`
+		case strings.HasSuffix(r.URL.Path, "/devices"):
+			_, _ = w.Write([]byte(`{"devices": [` + device + `, {"id": "456", "nodeId": "n456"}]}`))
+		default:
+			_, _ = w.Write([]byte(device))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}
+	setProviderState(client, &providerState{deviceCache: newDeviceCache(time.Hour)})
+	ctx := context.Background()
+
+	// Concurrent reads of different devices, by legacy ID and node ID, share a
+	// single list request.
+	var wg sync.WaitGroup
+	for _, id := range []string{"123", "n123", "456", "n456"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := getDevice(ctx, client, id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := listDevices(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := requests["GET /api/v2/tailnet/example.com/devices"]; got != 1 {
+		t.Errorf("expected 1 list request, got %d", got)
+	}
+	if got := requests["GET /api/v2/device/123"] + requests["GET /api/v2/device/n123"]; got != 0 {
+		t.Errorf("expected no device requests, got %d", got)
+	}
+
+	// A changed device is read from the API.
+	if err := client.Devices().SetTags(ctx, "123", []string{"tag:new"}); err != nil {
+		t.Fatal(err)
+	}
+	invalidateDevice(client, "123")
+
+	device, err := getDevice(ctx, client, "n123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(device.Tags) != 1 || device.Tags[0] != "tag:new" {
+		t.Errorf("expected the changed device to be read from the API, got tags %v", device.Tags)
+	}
+	if got := requests["GET /api/v2/device/n123"]; got != 1 {
+		t.Errorf("expected 1 device request, got %d", got)
+	}
+
+	// Other devices are still served from the cache, but the list is fetched
+	// again.
+	if _, err := getDevice(ctx, client, "456"); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests["GET /api/v2/device/456"]; got != 0 {
+		t.Errorf("expected no device requests, got %d", got)
+	}
+	if _, err := listDevices(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests["GET /api/v2/tailnet/example.com/devices"]; got != 2 {
+		t.Errorf("expected 2 list requests, got %d", got)
+	}
+}
+
+func TestDeviceCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	var listRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listRequests++
+		_, _ = w.Write([]byte(`{"devices": [{"id": "123", "nodeId": "n123"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}
+	setProviderState(client, &providerState{deviceCache: newDeviceCache(10 * time.Millisecond)})
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := getDevice(ctx, client, "123"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := getDevice(ctx, client, "123"); err != nil {
+		t.Fatal(err)
+	}
+
+	if listRequests != 2 {
+		t.Errorf("expected the list to be fetched again after the TTL, got %d list requests", listRequests)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/oauth2"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// oauthAccessTokenEphemeralResource obtains an API access token for an OAuth
+// client or a federated identity, the same way as the provider does for its
+// own credentials.
+type oauthAccessTokenEphemeralResource struct {
+	client *tailscale.Client
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure      = &oauthAccessTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &oauthAccessTokenEphemeralResource{}
+)
+
+type oauthAccessTokenEphemeralModel struct {
+	ClientID      types.String `tfsdk:"client_id"`
+	ClientSecret  types.String `tfsdk:"client_secret"`
+	IdentityToken types.String `tfsdk:"identity_token"`
+	Scopes        []string     `tfsdk:"scopes"`
+	AccessToken   types.String `tfsdk:"access_token"`
+	TokenType     types.String `tfsdk:"token_type"`
+	ExpiresAt     types.String `tfsdk:"expires_at"`
+}
+
+func newOAuthAccessTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &oauthAccessTokenEphemeralResource{}
+}
+
+func (r *oauthAccessTokenEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_oauth_access_token"
+}
+
+func (r *oauthAccessTokenEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The ephemeral oauth_access_token resource obtains a short-lived API access token for an OAuth client, using its secret, or for a federated identity, using a JWT issued by its identity provider. The token is never stored in the state or plan. Use it to pass API credentials to other providers or tools. Requires Terraform 1.10 or later. See https://tailscale.com/kb/1215/oauth-clients and https://tailscale.com/kb/1581/workload-identity-federation for more information.",
+		Attributes: map[string]schema.Attribute{
+			"client_id": schema.StringAttribute{
+				Required:    true,
+				Description: "The ID of the OAuth client or federated identity.",
+			},
+			"client_secret": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The secret of the OAuth client. Conflicts with 'identity_token'.",
+			},
+			"identity_token": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The jwt identity token to exchange for an access token of the federated identity. Conflicts with 'client_secret'.",
+			},
+			"scopes": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The OAuth 2.0 scopes to request for the access token, which must be a subset of the scopes of the OAuth client. Defaults to all the scopes of the OAuth client. Only valid with 'client_secret'. See https://tailscale.com/kb/1623/trust-credentials#scopes for available scopes.",
+			},
+			"access_token": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The API access token, which can be used in place of an API key.",
+			},
+			"token_type": schema.StringAttribute{
+				Computed:    true,
+				Description: "The type of the access token, such as `Bearer`.",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The expiry timestamp of the access token in RFC3339 format",
+			},
+		},
+	}
+}
+
+func (r *oauthAccessTokenEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// The provider is not configured yet when validating the configuration.
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*tailscale.Client)
+}
+
+func (r *oauthAccessTokenEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var data oauthAccessTokenEphemeralModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.ClientSecret.IsUnknown() || data.IdentityToken.IsUnknown() {
+		return
+	}
+
+	switch {
+	case data.ClientSecret.IsNull() && data.IdentityToken.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("client_secret"), "Missing credentials", "One of 'client_secret' or 'identity_token' must be set.")
+	case !data.ClientSecret.IsNull() && !data.IdentityToken.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("identity_token"), "Conflicting credentials", "'client_secret' conflicts with 'identity_token'.")
+	case !data.IdentityToken.IsNull() && len(data.Scopes) > 0:
+		resp.Diagnostics.AddAttributeError(path.Root("scopes"), "Invalid scopes", "'scopes' can only be requested with 'client_secret', access tokens of federated identities have all the scopes of the federated identity.")
+	}
+}
+
+func (r *oauthAccessTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data oauthAccessTokenEphemeralModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if headscaleClientFor(r.client) != nil {
+		resp.Diagnostics.AddError("Failed to obtain access token", "the ephemeral tailscale_oauth_access_token is not supported by the headscale backend")
+		return
+	}
+
+	var auth tailscale.Auth
+	if !data.ClientSecret.IsNull() {
+		auth = &providerOAuth{tailscale.OAuth{
+			ClientID:     data.ClientID.ValueString(),
+			ClientSecret: data.ClientSecret.ValueString(),
+			Scopes:       data.Scopes,
+		}}
+	} else {
+		idToken := data.IdentityToken.ValueString()
+		auth = &tailscale.IdentityFederation{
+			ClientID:    data.ClientID.ValueString(),
+			IDTokenFunc: func() (string, error) { return idToken, nil },
+		}
+	}
+
+	// The token is requested with the HTTP client of the provider, without
+	// the credentials of the provider.
+	httpClient, err := providerHTTPClientFor(r.client)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to obtain access token", err.Error())
+		return
+	}
+	token, err := accessToken(auth, httpClient, r.client.BaseURL.String())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to obtain access token", err.Error())
+		return
+	}
+
+	data.AccessToken = types.StringValue(token.AccessToken)
+	data.TokenType = types.StringValue(token.Type())
+	data.ExpiresAt = types.StringValue(token.Expiry.UTC().Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// accessToken returns an access token obtained with the given
+// authentication, which is that of a tailscale.Client. The token is requested
+// with httpClient, which providerOAuth and tailscale.IdentityFederation both
+// use for their token requests.
+func accessToken(auth tailscale.Auth, httpClient *http.Client, baseURL string) (*oauth2.Token, error) {
+	transport, ok := auth.HTTPClient(httpClient, baseURL).Transport.(*oauth2.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected transport of %T", auth)
+	}
+	return transport.Source.Token()
+}
@@ -0,0 +1,166 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+func TestProvider_EphemeralOAuthAccessToken(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		scopes     []string
+		authorized []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		writeToken := func(token string) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+		}
+		switch r.URL.Path {
+		case "/api/v2/oauth/token":
+			id, secret, _ := r.BasicAuth()
+			if id != "client-id" || secret != "tskey-client-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			scopes = append(scopes, r.FormValue("scope"))
+			writeToken("tskey-access-oauth")
+		case "/api/v2/oauth/token-exchange":
+			if r.FormValue("client_id") != "client-id" || r.FormValue("jwt") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writeToken("tskey-access-federated")
+		case "/api/v2/tailnet/example.com/devices":
+			token, _, _ := r.BasicAuth()
+			authorized = append(authorized, token)
+			_, _ = w.Write([]byte(`{"devices": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	claims, _ := json.Marshal(map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+	jwt := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(claims) + ".signature"
+
+	// The access token configures a second provider, as ephemeral values
+	// cannot be stored.
+	config := func(credentials string) string {
+		return fmt.Sprintf(`
+			provider "tailscale" {
+				api_key  = "tskey-api-test"
+				tailnet  = "example.com"
+				base_url = %[1]q
+			}
+
+			ephemeral "tailscale_oauth_access_token" "test" {
+				client_id = "client-id"
+				%[2]s
+			}
+
+			provider "tailscale" {
+				alias    = "bootstrapped"
+				api_key  = ephemeral.tailscale_oauth_access_token.test.access_token
+				tailnet  = "example.com"
+				base_url = %[1]q
+			}
+
+			data "tailscale_devices" "all" {
+				provider = tailscale.bootstrapped
+			}`, server.URL, credentials)
+	}
+	checkAuthorized := func(token string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			mu.Lock()
+			defer mu.Unlock()
+			defer func() { authorized = nil }()
+			// Refreshing the state of the previous step uses its token too.
+			if !slices.Contains(authorized, token) {
+				return fmt.Errorf("expected devices to be listed with %q, got %v", token, authorized)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      config(""),
+				ExpectError: regexp.MustCompile(`One of 'client_secret' or 'identity_token' must be set`),
+			},
+			{
+				Config: config(fmt.Sprintf(`
+					identity_token = %q
+					scopes         = ["devices:core:read"]`, jwt)),
+				ExpectError: regexp.MustCompile(`'scopes' can only be requested with 'client_secret'`),
+			},
+			{
+				Config: config(`
+					client_secret = "tskey-client-secret"
+					scopes        = ["devices:core:read"]`),
+				Check: checkAuthorized("tskey-access-oauth"),
+			},
+			{
+				Config: config(fmt.Sprintf(`identity_token = %q`, jwt)),
+				Check:  checkAuthorized("tskey-access-federated"),
+			},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(scopes) == 0 || slices.ContainsFunc(scopes, func(s string) bool { return s != "devices:core:read" }) {
+		t.Errorf("expected the scopes to be requested, got %v", scopes)
+	}
+}
+
+func TestAccessTokenHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "tskey-access-test", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	t.Cleanup(server.Close)
+
+	claims, _ := json.Marshal(map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+	jwt := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(claims) + ".signature"
+
+	for name, auth := range map[string]tailscale.Auth{
+		"oauth": &providerOAuth{tailscale.OAuth{ClientID: "client-id", ClientSecret: "tskey-client-secret"}},
+		"identity federation": &tailscale.IdentityFederation{
+			ClientID:    "client-id",
+			IDTokenFunc: func() (string, error) { return jwt, nil },
+		},
+	} {
+		// Only the given client trusts the certificate of the server.
+		token, err := accessToken(auth, server.Client(), server.URL)
+		if err != nil {
+			t.Errorf("%s: expected the token to be requested with the given client, got %v", name, err)
+		} else if token.AccessToken != "tskey-access-test" {
+			t.Errorf("%s: unexpected access token %q", name, token.AccessToken)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// defaultEphemeralKeyExpiry is the expiry of keys opened by the ephemeral
+// tailscale_tailnet_key, which only need to be valid for a single run.
+const defaultEphemeralKeyExpiry = time.Hour
+
+// tailnetKeyEphemeralResource creates an auth key when opened and deletes it
+// when closed, so that the key is never stored in the state.
+type tailnetKeyEphemeralResource struct {
+	client *tailscale.Client
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure = &tailnetKeyEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithClose     = &tailnetKeyEphemeralResource{}
+)
+
+type tailnetKeyEphemeralModel struct {
+	Tailnet       types.String `tfsdk:"tailnet"`
+	Reusable      types.Bool   `tfsdk:"reusable"`
+	Ephemeral     types.Bool   `tfsdk:"ephemeral"`
+	Preauthorized types.Bool   `tfsdk:"preauthorized"`
+	Tags          []string     `tfsdk:"tags"`
+	Expiry        types.Int64  `tfsdk:"expiry"`
+	Description   types.String `tfsdk:"description"`
+	ID            types.String `tfsdk:"id"`
+	Key           types.String `tfsdk:"key"`
+	CreatedAt     types.String `tfsdk:"created_at"`
+	ExpiresAt     types.String `tfsdk:"expires_at"`
+}
+
+// tailnetKeyPrivate is the private data of an opened key, used to delete it
+// when it is closed.
+type tailnetKeyPrivate struct {
+	ID      string `json:"id"`
+	Tailnet string `json:"tailnet,omitempty"`
+}
+
+func newTailnetKeyEphemeralResource() ephemeral.EphemeralResource {
+	return &tailnetKeyEphemeralResource{}
+}
+
+func (r *tailnetKeyEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tailnet_key"
+}
+
+func (r *tailnetKeyEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The ephemeral tailnet_key resource creates a short-lived pre-authentication key, which is deleted at the end of each Terraform run and never stored in the state or plan. Use it to pass a key to a provider block or to a write-only argument. Requires Terraform 1.10 or later. See https://tailscale.com/kb/1085/auth-keys for more information.",
+		Attributes: map[string]schema.Attribute{
+			"tailnet": schema.StringAttribute{
+				Optional:    true,
+				Description: "The tailnet to create the key in. Defaults to the provider tailnet.",
+			},
+			"reusable": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Indicates if the key is reusable or single-use. Defaults to `false`.",
+			},
+			"ephemeral": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Indicates if the key is ephemeral. Defaults to `false`.",
+			},
+			"preauthorized": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Determines whether or not the machines authenticated by the key will be authorized for the tailnet by default. Defaults to `false`.",
+			},
+			"tags": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "List of tags to apply to the machines authenticated by the key. The `default_tags` of the provider are added to these tags, and all tags must match the `allowed_tag_patterns` of the provider.",
+			},
+			"expiry": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("The expiry of the key in seconds. Defaults to `%d` (1 hour).", int64(defaultEphemeralKeyExpiry.Seconds())),
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the key consisting of alphanumeric characters. Defaults to `\"\"`.",
+			},
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the key.",
+			},
+			"key": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The authentication key",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The creation timestamp of the key in RFC3339 format",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The expiry timestamp of the key in RFC3339 format",
+			},
+		},
+	}
+}
+
+func (r *tailnetKeyEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// The provider is not configured yet when validating the configuration.
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*tailscale.Client)
+}
+
+// tailnetClient returns the client for the given tailnet, after checking
+// that the provider may create and delete keys.
+func (r *tailnetKeyEphemeralResource) tailnetClient(tailnet string) (*tailscale.Client, error) {
+	switch {
+	case isReadOnly(r.client):
+		return nil, fmt.Errorf("the provider is read-only")
+	case headscaleClientFor(r.client) != nil:
+		return nil, fmt.Errorf("the ephemeral tailscale_tailnet_key is not supported by the headscale backend")
+	}
+	return tailnetClient(r.client, tailnet), nil
+}
+
+func (r *tailnetKeyEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data tailnetKeyEphemeralModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(data.Description.ValueString()) > 50 {
+		resp.Diagnostics.AddAttributeError(path.Root("description"), "Invalid description", "description must be 50 characters or less")
+		return
+	}
+
+	tags := data.Tags
+	if policy := tagPolicyFor(r.client); policy != nil {
+		var err error
+		if tags, err = policy.merge(tags); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("tags"), "Invalid tags", err.Error())
+			return
+		}
+	}
+
+	client, err := r.tailnetClient(data.Tailnet.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create key", err.Error())
+		return
+	}
+
+	var createReq tailscale.CreateKeyRequest
+	createReq.Capabilities.Devices.Create.Reusable = data.Reusable.ValueBool()
+	createReq.Capabilities.Devices.Create.Ephemeral = data.Ephemeral.ValueBool()
+	createReq.Capabilities.Devices.Create.Preauthorized = data.Preauthorized.ValueBool()
+	createReq.Capabilities.Devices.Create.Tags = tags
+	createReq.ExpirySeconds = int64(defaultEphemeralKeyExpiry.Seconds())
+	if !data.Expiry.IsNull() {
+		createReq.ExpirySeconds = data.Expiry.ValueInt64()
+	}
+	createReq.Description = data.Description.ValueString()
+
+	key, err := client.Keys().CreateAuthKey(ctx, createReq)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create key", err.Error())
+		return
+	}
+
+	private, err := json.Marshal(tailnetKeyPrivate{ID: key.ID, Tailnet: data.Tailnet.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create key", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, "key", private)...)
+
+	data.ID = types.StringValue(key.ID)
+	data.Key = types.StringValue(key.Key)
+	data.CreatedAt = types.StringValue(key.Created.Format(time.RFC3339))
+	data.ExpiresAt = types.StringValue(key.Expires.Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+func (r *tailnetKeyEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	raw, diags := req.Private.GetKey(ctx, "key")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || raw == nil {
+		return
+	}
+
+	var private tailnetKeyPrivate
+	if err := json.Unmarshal(raw, &private); err != nil {
+		resp.Diagnostics.AddError("Failed to delete key", err.Error())
+		return
+	}
+
+	client, err := r.tailnetClient(private.Tailnet)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to delete key", err.Error())
+		return
+	}
+
+	// Single-use keys may no longer be here, so deletions failing with
+	// not-found errors are ignored.
+	if err := client.Keys().Delete(ctx, private.ID); err != nil && !tailscale.IsNotFound(err) {
+		resp.Diagnostics.AddError("Failed to delete key", err.Error())
+	}
+}
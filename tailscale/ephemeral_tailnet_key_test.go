@@ -0,0 +1,102 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+func TestProvider_EphemeralTailnetKey(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created []tailscale.CreateKeyRequest
+		deleted []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/tailnet/example.com/keys":
+			var body tailscale.CreateKeyRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body)
+			id := fmt.Sprintf("k%d", len(created))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":      id,
+				"key":     "tskey-auth-" + id,
+				"created": time.Now().UTC(),
+				"expires": time.Now().UTC().Add(time.Hour),
+			})
+		case r.Method == http.MethodDelete && len(r.URL.Path) > len("/api/v2/tailnet/example.com/keys/"):
+			deleted = append(deleted, r.URL.Path[len("/api/v2/tailnet/example.com/keys/"):])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	providerConfig := func(settings string) string {
+		return fmt.Sprintf(`
+			provider "tailscale" {
+				api_key      = "tskey-api-test"
+				tailnet      = "example.com"
+				base_url     = %q
+				default_tags = ["tag:team-base"]
+				%s
+			}
+
+			ephemeral "tailscale_tailnet_key" "test" {
+				reusable    = true
+				tags        = ["tag:ci"]
+				description = "CI runner"
+			}`, server.URL, settings)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(""),
+			},
+			{
+				Config:      providerConfig("read_only = true"),
+				ExpectError: regexp.MustCompile(`the provider is read-only`),
+			},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(created) == 0 {
+		t.Fatal("expected keys to be created")
+	}
+	want := tailscale.CreateKeyRequest{ExpirySeconds: 3600, Description: "CI runner"}
+	want.Capabilities.Devices.Create.Reusable = true
+	want.Capabilities.Devices.Create.Tags = []string{"tag:ci", "tag:team-base"}
+	for _, body := range created {
+		if err := assertEqual(want, body, "wrong key created"); err != nil {
+			t.Error(err)
+		}
+	}
+	var wantDeleted []string
+	for i := range created {
+		wantDeleted = append(wantDeleted, fmt.Sprintf("k%d", i+1))
+	}
+	if err := assertEqual(wantDeleted, deleted, "expected every key to be deleted when closed"); err != nil {
+		t.Error(err)
+	}
+}
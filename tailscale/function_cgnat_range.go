@@ -0,0 +1,38 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"tailscale.com/net/tsaddr"
+)
+
+// cgnatRangeFunction returns the CGNAT range that Tailscale assigns IPv4
+// device addresses from.
+type cgnatRangeFunction struct{}
+
+var _ function.Function = &cgnatRangeFunction{}
+
+func newCGNATRangeFunction() function.Function {
+	return &cgnatRangeFunction{}
+}
+
+func (f *cgnatRangeFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cgnat_range"
+}
+
+func (f *cgnatRangeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "The Tailscale IPv4 range",
+		Description: "Returns the CGNAT range (`100.64.0.0/10`) that Tailscale assigns IPv4 device addresses from. See https://tailscale.com/kb/1015/100.x-addresses for more information.",
+		Return:      function.StringReturn{},
+	}
+}
+
+func (f *cgnatRangeFunction) Run(ctx context.Context, _ function.RunRequest, resp *function.RunResponse) {
+	resp.Error = resp.Result.Set(ctx, tsaddr.CGNATRange().String())
+}
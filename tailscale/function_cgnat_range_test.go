@@ -0,0 +1,29 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testFunctionCGNATRange = testRequiredProviders + `
+output "range" {
+  value = provider::tailscale::cgnat_range()
+}
+`
+
+func TestProvider_FunctionCGNATRange(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testFunctionCGNATRange,
+				Check:  resource.TestCheckOutput("range", "100.64.0.0/10"),
+			},
+		},
+	})
+}
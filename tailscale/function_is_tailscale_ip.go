@@ -0,0 +1,57 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"tailscale.com/net/tsaddr"
+)
+
+// isTailscaleIPFunction reports whether an address is in one of the ranges
+// that Tailscale assigns device addresses from.
+type isTailscaleIPFunction struct{}
+
+var _ function.Function = &isTailscaleIPFunction{}
+
+func newIsTailscaleIPFunction() function.Function {
+	return &isTailscaleIPFunction{}
+}
+
+func (f *isTailscaleIPFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_tailscale_ip"
+}
+
+func (f *isTailscaleIPFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Check whether an address is a Tailscale IP",
+		Description: "Returns `true` if the given IPv4 or IPv6 address is in a range that Tailscale assigns device addresses from, and `false` otherwise. See https://tailscale.com/kb/1033/ip-and-dns-addresses for more information.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "addr",
+				Description: "The IPv4 or IPv6 address to check",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *isTailscaleIPFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var addr string
+	resp.Error = req.Arguments.Get(ctx, &addr)
+	if resp.Error != nil {
+		return
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Provided address is invalid: "+err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, tsaddr.IsTailscaleIP(ip))
+}
@@ -0,0 +1,46 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testFunctionIsTailscaleIP = testRequiredProviders + `
+output "ipv4" {
+  value = provider::tailscale::is_tailscale_ip("100.101.102.103")
+}
+
+output "ipv6" {
+  value = provider::tailscale::is_tailscale_ip("fd7a:115c:a1e0::1")
+}
+
+output "other" {
+  value = provider::tailscale::is_tailscale_ip("192.168.1.1")
+}
+`
+
+func TestProvider_FunctionIsTailscaleIP(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testFunctionIsTailscaleIP,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("ipv4", "true"),
+					resource.TestCheckOutput("ipv6", "true"),
+					resource.TestCheckOutput("other", "false"),
+				),
+			},
+			{
+				Config:      testRequiredProviders + `output "ipv4" { value = provider::tailscale::is_tailscale_ip("100.64.0.0/10") }`,
+				ExpectError: regexp.MustCompile(`Provided address is invalid`),
+			},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"encoding/binary"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"tailscale.com/net/tsaddr"
+)
+
+// unmapVia6Function reverses via6Function, returning the site ID and IPv4
+// CIDR of a 4via6 address or prefix.
+type unmapVia6Function struct{}
+
+var _ function.Function = &unmapVia6Function{}
+
+type unmapVia6Result struct {
+	Site int64  `tfsdk:"site"`
+	CIDR string `tfsdk:"cidr"`
+}
+
+func newUnmapVia6Function() function.Function {
+	return &unmapVia6Function{}
+}
+
+func (f *unmapVia6Function) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "unmap_via6"
+}
+
+func (f *unmapVia6Function) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Unmap a 4via6 address or prefix",
+		Description: "Returns an object with the `site` ID and the IPv4 `cidr` of a 4via6 address or prefix, such as those calculated by the `via6` function. A single address is unmapped to a `/32` CIDR. See Tailscale documentation for [4via6 subnets](https://tailscale.com/kb/1201/4via6-subnets/) for more details.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "addr",
+				Description: "The 4via6 address or prefix to unmap",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"site": types.Int64Type,
+				"cidr": types.StringType,
+			},
+		},
+	}
+}
+
+func (f *unmapVia6Function) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var addr string
+	resp.Error = req.Arguments.Get(ctx, &addr)
+	if resp.Error != nil {
+		return
+	}
+
+	var prefix netip.Prefix
+	var err error
+	if strings.Contains(addr, "/") {
+		prefix, err = netip.ParsePrefix(addr)
+	} else {
+		var ip netip.Addr
+		ip, err = netip.ParseAddr(addr)
+		prefix = netip.PrefixFrom(ip, ip.BitLen())
+	}
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Provided address is invalid: "+err.Error())
+		return
+	}
+
+	// The site ID and IPv4 address take up the last 64 bits of the prefix,
+	// so the prefix must cover at least the site ID.
+	if !tsaddr.IsViaPrefix(prefix) || prefix.Bits() < 96 {
+		resp.Error = function.NewArgumentFuncError(0, addr+" is not a 4via6 address or prefix")
+		return
+	}
+
+	ip := prefix.Addr().As16()
+	v4 := netip.PrefixFrom(tsaddr.UnmapVia(prefix.Addr()), prefix.Bits()-96).Masked()
+	resp.Error = resp.Result.Set(ctx, unmapVia6Result{
+		Site: int64(binary.BigEndian.Uint32(ip[8:12])),
+		CIDR: v4.String(),
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testFunctionUnmapVia6 = testRequiredProviders + `
+locals {
+  prefix  = provider::tailscale::unmap_via6(provider::tailscale::via6(7, "10.1.1.0/24"))
+  address = provider::tailscale::unmap_via6("fd7a:115c:a1e0:b1a:0:7:a01:105")
+  # A prefix with host bits set is unmapped to a canonical CIDR.
+  host_bits = provider::tailscale::unmap_via6("fd7a:115c:a1e0:b1a:0:7:a01:101/120")
+}
+
+output "prefix_site" {
+  value = local.prefix.site
+}
+
+output "prefix_cidr" {
+  value = local.prefix.cidr
+}
+
+output "address_site" {
+  value = local.address.site
+}
+
+output "address_cidr" {
+  value = local.address.cidr
+}
+
+output "host_bits_cidr" {
+  value = local.host_bits.cidr
+}
+`
+
+func TestProvider_FunctionUnmapVia6(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testFunctionUnmapVia6,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("prefix_site", "7"),
+					resource.TestCheckOutput("prefix_cidr", "10.1.1.0/24"),
+					resource.TestCheckOutput("address_site", "7"),
+					resource.TestCheckOutput("address_cidr", "10.1.1.5/32"),
+					resource.TestCheckOutput("host_bits_cidr", "10.1.1.0/24"),
+				),
+			},
+			{
+				Config:      testRequiredProviders + `output "prefix" { value = provider::tailscale::unmap_via6("fd7a:115c:a1e0::1") }`,
+				ExpectError: regexp.MustCompile(`is not a 4via6 address\s+or prefix`),
+			},
+		},
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"math"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"tailscale.com/net/tsaddr"
+)
+
+// via6Function maps an IPv4 CIDR of a site to its 4via6 prefix, as the
+// tailscale_4via6 data source does.
+type via6Function struct{}
+
+var _ function.Function = &via6Function{}
+
+func newVia6Function() function.Function {
+	return &via6Function{}
+}
+
+func (f *via6Function) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "via6"
+}
+
+func (f *via6Function) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Map an IPv4 CIDR to a 4via6 prefix",
+		Description: "Calculates the IPv6 prefix for a given site ID and IPv4 CIDR. See Tailscale documentation for [4via6 subnets](https://tailscale.com/kb/1201/4via6-subnets/) for more details.",
+		Parameters: []function.Parameter{
+			function.Int64Parameter{
+				Name:        "site",
+				Description: "Site ID (between 0 and 65535)",
+			},
+			function.StringParameter{
+				Name:        "cidr",
+				Description: "The IPv4 CIDR to map",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *via6Function) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var site int64
+	var cidr string
+	resp.Error = req.Arguments.Get(ctx, &site, &cidr)
+	if resp.Error != nil {
+		return
+	}
+
+	if site < 0 || site > math.MaxUint16 {
+		resp.Error = function.NewArgumentFuncError(0, "site must be between 0 and 65535")
+		return
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Provided CIDR is invalid: "+err.Error())
+		return
+	}
+
+	via, err := tsaddr.MapVia(uint32(site), prefix)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Failed to map 4via6 address: "+err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, via.String())
+}
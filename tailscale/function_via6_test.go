@@ -0,0 +1,38 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testFunctionVia6 = testRequiredProviders + `
+output "ipv6" {
+  value = provider::tailscale::via6(7, "10.1.1.0/24")
+}
+`
+
+func TestProvider_FunctionVia6(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testFunctionVia6,
+				Check:  resource.TestCheckOutput("ipv6", "fd7a:115c:a1e0:b1a:0:7:a01:100/120"),
+			},
+			{
+				Config:      testRequiredProviders + `output "ipv6" { value = provider::tailscale::via6(70000, "10.1.1.0/24") }`,
+				ExpectError: regexp.MustCompile(`site must be between 0 and 65535`),
+			},
+			{
+				Config:      testRequiredProviders + `output "ipv6" { value = provider::tailscale::via6(7, "fd00::/64") }`,
+				ExpectError: regexp.MustCompile(`want IPv4\s+CIDR with a site ID`),
+			},
+		},
+	})
+}
@@ -0,0 +1,288 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// Backends supported by the backend argument.
+const (
+	backendTailscale = "tailscale"
+	backendHeadscale = "headscale"
+)
+
+// headscaleClient is a client of the REST API of a Headscale control server,
+// see https://headscale.net/stable/ref/api/.
+type headscaleClient struct {
+	baseURL   *url.URL
+	apiKey    string
+	userAgent string
+	http      *http.Client
+}
+
+// headscaleClientFor returns the Headscale client of the provider, or nil if
+// the provider uses the Tailscale API.
+func headscaleClientFor(m interface{}) *headscaleClient {
+	client, ok := m.(*tailscale.Client)
+	if !ok {
+		return nil
+	}
+	return providerStateFor(client).headscale
+}
+
+// do sends a request to /api/v1/... with the given JSON body, if not nil, and
+// decodes the JSON response into out, if not nil. Failed requests return an
+// error decoded by apiError, so that errors are handled as those of the
+// Tailscale API.
+func (c *headscaleClient) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := c.baseURL.JoinPath("/api/v1", path)
+	u.RawQuery = query.Encode()
+
+	var raw []byte
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		// Errors are returned by the gRPC gateway of Headscale as
+		// {"code": 5, "message": "...", "details": []}, whose message is
+		// decoded by apiError.
+		return apiError(resp.StatusCode, respBody)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// headscaleUser is a Headscale user.
+type headscaleUser struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	CreatedAt     time.Time `json:"createdAt"`
+	DisplayName   string    `json:"displayName"`
+	Email         string    `json:"email"`
+	ProfilePicURL string    `json:"profilePicUrl"`
+}
+
+// headscalePreAuthKey is a Headscale pre-authentication key, which is the
+// Headscale equivalent of a Tailscale auth key.
+type headscalePreAuthKey struct {
+	ID         string         `json:"id"`
+	User       *headscaleUser `json:"user"`
+	Key        string         `json:"key"`
+	Reusable   bool           `json:"reusable"`
+	Ephemeral  bool           `json:"ephemeral"`
+	Used       bool           `json:"used"`
+	Expiration time.Time      `json:"expiration"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	ACLTags    []string       `json:"aclTags"`
+}
+
+// headscaleNode is a Headscale node, which is the Headscale equivalent of a
+// Tailscale device.
+type headscaleNode struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	GivenName      string         `json:"givenName"`
+	User           *headscaleUser `json:"user"`
+	IPAddresses    []string       `json:"ipAddresses"`
+	ForcedTags     []string       `json:"forcedTags"`
+	ApprovedRoutes []string       `json:"approvedRoutes"`
+	Online         bool           `json:"online"`
+}
+
+// headscaleResource declares how a resource or data source is supported by
+// the headscale backend.
+type headscaleResource struct {
+	// unsupported are the arguments that cannot be set with the headscale
+	// backend, with the reason why.
+	unsupported map[string]string
+
+	create        schema.CreateContextFunc
+	read          schema.ReadContextFunc
+	update        schema.UpdateContextFunc
+	delete        schema.DeleteContextFunc
+	customizeDiff schema.CustomizeDiffFunc
+	// importer is used instead of the importer of the resource if set.
+	importer schema.StateContextFunc
+}
+
+// headscaleResources and headscaleDataSources declare the resources and data
+// sources supported by the headscale backend. The others cannot be used with
+// the headscale backend.
+var (
+	headscaleResources = map[string]*headscaleResource{
+		"tailscale_acl":                  headscaleACL,
+		"tailscale_device_subnet_routes": headscaleDeviceSubnetRoutes,
+		"tailscale_device_tags":          headscaleDeviceTags,
+		"tailscale_tailnet_key":          headscaleTailnetKey,
+	}
+	headscaleDataSources = map[string]*headscaleResource{
+		"tailscale_user": headscaleUserDataSource,
+	}
+)
+
+// headscaleUnsupportedError returns the error for a resource or data source
+// that is not supported by the headscale backend.
+func headscaleUnsupportedError(name string) diag.Diagnostics {
+	return diag.Errorf("%s is not supported by the headscale backend", name)
+}
+
+// withHeadscale makes a resource or data source use the functions declared
+// by hs instead of its own with the headscale backend, or fail if hs is nil.
+func withHeadscale(r *schema.Resource, name string, hs *headscaleResource) {
+	isResource := r.CreateContext != nil
+	crud := func(fn, hsFn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if headscaleClientFor(m) == nil {
+				return fn(ctx, d, m)
+			}
+			if hs == nil || hsFn == nil {
+				return headscaleUnsupportedError(name)
+			}
+			if !isResource {
+				// Data sources have no plan in which to check their arguments.
+				if diags := checkHeadscaleArguments(d.GetRawConfig(), name, hs); diags.HasError() {
+					return diags
+				}
+			}
+			return hsFn(ctx, d, m)
+		}
+	}
+
+	var hsCreate, hsRead, hsUpdate, hsDelete func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+	if hs != nil {
+		hsCreate, hsRead, hsUpdate, hsDelete = hs.create, hs.read, hs.update, hs.delete
+	}
+	r.CreateContext = crud(r.CreateContext, hsCreate)
+	r.ReadContext = crud(r.ReadContext, hsRead)
+	r.UpdateContext = crud(r.UpdateContext, hsUpdate)
+	r.DeleteContext = crud(r.DeleteContext, hsDelete)
+
+	if !isResource {
+		return
+	}
+
+	customizeDiff := r.CustomizeDiff
+	r.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if headscaleClientFor(m) == nil {
+			if customizeDiff == nil {
+				return nil
+			}
+			return customizeDiff(ctx, d, m)
+		}
+		if hs == nil {
+			return diagnosticsAsError(headscaleUnsupportedError(name))
+		}
+		if diags := checkHeadscaleArguments(d.GetRawConfig(), name, hs); diags.HasError() {
+			return diagnosticsAsError(diags)
+		}
+		if hs.customizeDiff == nil {
+			return nil
+		}
+		return hs.customizeDiff(ctx, d, m)
+	}
+
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		importer := r.Importer.StateContext
+		r.Importer = &schema.ResourceImporter{
+			StateContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+				if headscaleClientFor(m) == nil {
+					return importer(ctx, d, m)
+				}
+				if hs == nil {
+					return nil, diagnosticsAsError(headscaleUnsupportedError(name))
+				}
+				if hs.importer != nil {
+					return hs.importer(ctx, d, m)
+				}
+				return importer(ctx, d, m)
+			},
+		}
+	}
+}
+
+// checkHeadscaleArguments returns an error for each argument set in config
+// that is not supported by the headscale backend.
+func checkHeadscaleArguments(config cty.Value, name string, hs *headscaleResource) diag.Diagnostics {
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+
+	unsupported := map[string]string{
+		"tailnet": "Headscale has a single tailnet.",
+	}
+	for arg, reason := range hs.unsupported {
+		unsupported[arg] = reason
+	}
+	args := make([]string, 0, len(unsupported))
+	for arg := range unsupported {
+		args = append(args, arg)
+	}
+	sort.Strings(args)
+
+	var diags diag.Diagnostics
+	for _, arg := range args {
+		if !config.Type().HasAttribute(arg) {
+			continue
+		}
+		value := config.GetAttr(arg)
+		// Explicitly setting the default value of a boolean is harmless.
+		if value.IsNull() || (value.IsKnown() && value.Type() == cty.Bool && value.False()) {
+			continue
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("Argument %q of %s is not supported by the headscale backend", arg, name),
+			Detail:        unsupported[arg],
+			AttributePath: cty.GetAttrPath(arg),
+		})
+	}
+	return diags
+}
@@ -0,0 +1,127 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// headscaleACL manages tailscale_acl resources as the policy of the
+// Headscale server, which must use the `database` policy mode. Headscale
+// validates the policy when it is set, and has no ETag.
+var headscaleACL = &headscaleResource{
+	unsupported: map[string]string{
+		"overwrite_concurrent_changes": "Headscale does not detect changes made to the policy outside of Terraform.",
+		"reset_acl_on_destroy":         "Headscale has no default policy to reset the policy to.",
+	},
+	create:        headscaleACLCreate,
+	read:          headscaleACLRead,
+	update:        headscaleACLUpdate,
+	delete:        schema.NoopContext,
+	customizeDiff: headscaleACLDiff,
+}
+
+// headscaleGetPolicy returns the policy of the Headscale server, which is
+// empty if no policy has been set.
+func headscaleGetPolicy(ctx context.Context, hs *headscaleClient) (string, error) {
+	var resp struct {
+		Policy string `json:"policy"`
+	}
+	err := hs.do(ctx, http.MethodGet, "policy", nil, nil, &resp)
+	if tailscale.IsNotFound(err) {
+		return "", nil
+	}
+	return resp.Policy, err
+}
+
+// headscaleSetPolicy sets the policy of the Headscale server.
+func headscaleSetPolicy(ctx context.Context, hs *headscaleClient, policy string) error {
+	return hs.do(ctx, http.MethodPut, "policy", nil, map[string]any{"policy": policy}, nil)
+}
+
+func headscaleACLRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy, err := headscaleGetPolicy(ctx, headscaleClientFor(m))
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	return setProperties(d, map[string]any{
+		"acl":  policy,
+		"etag": "",
+	})
+}
+
+func headscaleACLCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	hs := headscaleClientFor(m)
+	acl := d.Get("acl").(string)
+
+	current, err := headscaleGetPolicy(ctx, hs)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+	// Headscale has no default policy, so the policy can only be overwritten
+	// without importing it if it has never been set.
+	if current != "" && !equivalentHuJSON(current, acl) && !d.Get("overwrite_existing_content").(bool) {
+		return diagnosticsError(errors.New(
+			"! You seem to be trying to overwrite an existing policy with a tailscale_acl resource.\n"+
+				"Before doing this, please import your existing policy into Terraform state using:\n"+
+				" terraform import $(this_resource) acl"), "Failed to set policy file")
+	}
+
+	var previous string
+	if d.Get("keep_previous_acl").(bool) {
+		previous = current
+	}
+	if err := headscaleSetPolicy(ctx, hs, acl); err != nil {
+		return diagnosticsError(err, "Failed to set policy file")
+	}
+
+	d.SetId(createUUID())
+	if err := d.Set("previous_acl", previous); err != nil {
+		return diagnosticsError(err, "Failed to set previous_acl")
+	}
+	return headscaleACLRead(ctx, d, m)
+}
+
+func headscaleACLUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	hs := headscaleClientFor(m)
+	if !d.HasChange("acl") {
+		return nil
+	}
+
+	previous, _ := d.GetChange("previous_acl")
+	if d.Get("keep_previous_acl").(bool) {
+		current, err := headscaleGetPolicy(ctx, hs)
+		if err != nil {
+			return diagnosticsError(err, "Failed to fetch policy file")
+		}
+		previous = current
+	}
+
+	if err := headscaleSetPolicy(ctx, hs, d.Get("acl").(string)); err != nil {
+		return diagnosticsError(err, "Failed to set policy file")
+	}
+
+	if err := d.Set("previous_acl", previous); err != nil {
+		return diagnosticsError(err, "Failed to set previous_acl")
+	}
+	return headscaleACLRead(ctx, d, m)
+}
+
+// headscaleACLDiff marks previous_acl as changing with the policy. The policy
+// is validated by Headscale when it is set, rather than when planning.
+func headscaleACLDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	oldACL, newACL := d.GetChange("acl")
+	if d.Id() != "" && d.Get("keep_previous_acl").(bool) && (!d.NewValueKnown("acl") || !equivalentHuJSON(oldACL.(string), newACL.(string))) {
+		return d.SetNewComputed("previous_acl")
+	}
+	return nil
+}
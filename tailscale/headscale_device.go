@@ -0,0 +1,135 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// headscaleDeviceTags manages tailscale_device_tags resources as the forced
+// tags of Headscale nodes. Device IDs are the IDs of Headscale nodes.
+var headscaleDeviceTags = &headscaleResource{
+	create:        headscaleDeviceTagsSet,
+	read:          headscaleDeviceTagsRead,
+	update:        headscaleDeviceTagsSet,
+	delete:        headscaleDeviceTagsDelete,
+	customizeDiff: resourceDeviceTagsDiff,
+}
+
+// headscaleDeviceSubnetRoutes manages tailscale_device_subnet_routes
+// resources as the approved routes of Headscale nodes.
+var headscaleDeviceSubnetRoutes = &headscaleResource{
+	create: headscaleDeviceSubnetRoutesCreate,
+	read:   headscaleDeviceSubnetRoutesRead,
+	update: headscaleDeviceSubnetRoutesUpdate,
+	delete: headscaleDeviceSubnetRoutesDelete,
+}
+
+// headscaleGetNode returns the node with the given ID.
+func headscaleGetNode(ctx context.Context, hs *headscaleClient, id string) (*headscaleNode, error) {
+	var resp struct {
+		Node headscaleNode `json:"node"`
+	}
+	if err := hs.do(ctx, http.MethodGet, "node/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Node, nil
+}
+
+// headscaleSetNodeTags sets the forced tags of the node with the given ID.
+func headscaleSetNodeTags(ctx context.Context, hs *headscaleClient, id string, tags []string) error {
+	return hs.do(ctx, http.MethodPost, "node/"+url.PathEscape(id)+"/tags", nil, map[string]any{"tags": tags}, nil)
+}
+
+// headscaleApproveNodeRoutes sets the approved routes of the node with the
+// given ID.
+func headscaleApproveNodeRoutes(ctx context.Context, hs *headscaleClient, id string, routes []string) error {
+	return hs.do(ctx, http.MethodPost, "node/"+url.PathEscape(id)+"/approve_routes", nil, map[string]any{"routes": routes}, nil)
+}
+
+// stringSet returns the elements of the set at key in the given resource.
+func stringSet(d *schema.ResourceData, key string) []string {
+	set := d.Get(key).(*schema.Set)
+	values := make([]string, set.Len())
+	for i, item := range set.List() {
+		values[i] = item.(string)
+	}
+	return values
+}
+
+func headscaleDeviceTagsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	node, err := headscaleGetNode(ctx, headscaleClientFor(m), d.Id())
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch device")
+	}
+
+	return setProperties(d, map[string]any{
+		"device_id": node.ID,
+		"tags":      node.ForcedTags,
+	})
+}
+
+func headscaleDeviceTagsSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	deviceID := d.Get("device_id").(string)
+	if err := headscaleSetNodeTags(ctx, headscaleClientFor(m), deviceID, stringSet(d, "tags")); err != nil {
+		return diagnosticsError(err, "Failed to set device tags")
+	}
+
+	d.SetId(deviceID)
+	return headscaleDeviceTagsRead(ctx, d, m)
+}
+
+func headscaleDeviceTagsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := headscaleSetNodeTags(ctx, headscaleClientFor(m), d.Get("device_id").(string), []string{}); err != nil {
+		return diagnosticsError(err, "Failed to set device tags")
+	}
+	return nil
+}
+
+func headscaleDeviceSubnetRoutesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	node, err := headscaleGetNode(ctx, headscaleClientFor(m), d.Get("device_id").(string))
+	if err != nil {
+		// If the device is not found, remove from the state so we can create it again.
+		if tailscale.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diagnosticsError(err, "Failed to fetch device subnet routes")
+	}
+
+	if err := d.Set("routes", node.ApprovedRoutes); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func headscaleDeviceSubnetRoutesCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := headscaleApproveNodeRoutes(ctx, headscaleClientFor(m), d.Get("device_id").(string), stringSet(d, "routes")); err != nil {
+		return diagnosticsError(err, "Failed to set device subnet routes")
+	}
+
+	d.SetId(createUUID())
+	return headscaleDeviceSubnetRoutesRead(ctx, d, m)
+}
+
+func headscaleDeviceSubnetRoutesUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := headscaleApproveNodeRoutes(ctx, headscaleClientFor(m), d.Get("device_id").(string), stringSet(d, "routes")); err != nil {
+		return diagnosticsError(err, "Failed to set device subnet routes")
+	}
+	return headscaleDeviceSubnetRoutesRead(ctx, d, m)
+}
+
+func headscaleDeviceSubnetRoutesDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := headscaleApproveNodeRoutes(ctx, headscaleClientFor(m), d.Get("device_id").(string), []string{}); err != nil {
+		return diagnosticsError(err, "Failed to set device subnet routes")
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// defaultKeyExpiry is the expiry of keys when 'expiry' is not set, as with
+// the Tailscale API.
+const defaultKeyExpiry = 90 * 24 * time.Hour
+
+// headscaleTailnetKey manages tailscale_tailnet_key resources as Headscale
+// pre-authentication keys, which belong to the user set by 'user_id'.
+var headscaleTailnetKey = &headscaleResource{
+	unsupported: map[string]string{
+		"preauthorized": "Devices registered with a Headscale pre-authentication key are always authorized.",
+		"description":   "Headscale pre-authentication keys have no description.",
+	},
+	create:        headscaleTailnetKeyCreate,
+	read:          headscaleTailnetKeyRead,
+	update:        schema.NoopContext,
+	delete:        headscaleTailnetKeyDelete,
+	customizeDiff: headscaleTailnetKeyDiff,
+	importer:      headscaleTailnetKeyImport,
+}
+
+func headscaleTailnetKeyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	hs := headscaleClientFor(m)
+	userID := d.Get("user_id").(string)
+	if userID == "" {
+		return diag.Errorf("'user_id' must be set to the ID of the Headscale user owning the key")
+	}
+
+	expiry := defaultKeyExpiry
+	if seconds, ok := d.GetOk("expiry"); ok {
+		expiry = time.Duration(seconds.(int)) * time.Second
+	}
+	tags := []string{}
+	for _, tag := range d.Get("tags").(*schema.Set).List() {
+		tags = append(tags, tag.(string))
+	}
+
+	req := map[string]any{
+		"user":       userID,
+		"reusable":   d.Get("reusable").(bool),
+		"ephemeral":  d.Get("ephemeral").(bool),
+		"expiration": time.Now().Add(expiry).UTC().Format(time.RFC3339),
+		"aclTags":    tags,
+	}
+	var resp struct {
+		PreAuthKey headscalePreAuthKey `json:"preAuthKey"`
+	}
+	if err := hs.do(ctx, http.MethodPost, "preauthkey", nil, req, &resp); err != nil {
+		return diagnosticsError(err, "Failed to create key")
+	}
+
+	d.SetId(resp.PreAuthKey.ID)
+	if err := d.Set("key", resp.PreAuthKey.Key); err != nil {
+		return diagnosticsError(err, "Failed to set key")
+	}
+	return headscaleTailnetKeyRead(ctx, d, m)
+}
+
+// headscaleGetPreAuthKey returns the pre-authentication key of the user with
+// the given ID, or a not found error.
+func headscaleGetPreAuthKey(ctx context.Context, hs *headscaleClient, userID, id string) (*headscalePreAuthKey, error) {
+	var resp struct {
+		PreAuthKeys []headscalePreAuthKey `json:"preAuthKeys"`
+	}
+	if err := hs.do(ctx, http.MethodGet, "preauthkey", url.Values{"user": {userID}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, key := range resp.PreAuthKeys {
+		if key.ID == id {
+			return &key, nil
+		}
+	}
+	return nil, apiError(http.StatusNotFound, []byte(fmt.Sprintf(`{"message": "pre-auth key %s not found"}`, id)))
+}
+
+// invalid reports whether the key can no longer be used, which is the case
+// when it has expired or, for single-use keys, been used.
+func (k *headscalePreAuthKey) invalid(now time.Time) bool {
+	return now.After(k.Expiration) || (k.Used && !k.Reusable)
+}
+
+func headscaleTailnetKeyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	recreateIfInvalid := shouldRecreateIfInvalid(d.Get("reusable").(bool), d.Get("recreate_if_invalid").(string))
+
+	key, err := headscaleGetPreAuthKey(ctx, headscaleClientFor(m), d.Get("user_id").(string), d.Id())
+	switch {
+	case tailscale.IsNotFound(err):
+		if recreateIfInvalid {
+			d.SetId("")
+		}
+		return nil
+	case err != nil:
+		return diagnosticsError(err, "Failed to fetch key")
+	}
+
+	invalid := key.invalid(time.Now())
+	if invalid && recreateIfInvalid {
+		d.SetId("")
+		return nil
+	}
+
+	userID := d.Get("user_id").(string)
+	if key.User != nil {
+		userID = key.User.ID
+	}
+	return setProperties(d, map[string]any{
+		"reusable":   key.Reusable,
+		"ephemeral":  key.Ephemeral,
+		"expiry":     int(key.Expiration.Sub(key.CreatedAt).Round(time.Second).Seconds()),
+		"created_at": key.CreatedAt.Format(time.RFC3339),
+		"expires_at": key.Expiration.Format(time.RFC3339),
+		"invalid":    invalid,
+		"user_id":    userID,
+		"tags":       key.ACLTags,
+	})
+}
+
+func headscaleTailnetKeyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	req := map[string]any{
+		"user": d.Get("user_id").(string),
+		"key":  d.Get("key").(string),
+	}
+	err := headscaleClientFor(m).do(ctx, http.MethodPost, "preauthkey/expire", nil, req, nil)
+	switch {
+	case tailscale.IsNotFound(err):
+		return nil
+	case err != nil:
+		return diagnosticsError(err, "Failed to expire key")
+	default:
+		return nil
+	}
+}
+
+// headscaleTailnetKeyDiff makes sure a resource is recreated when a
+// `recreate_if_invalid` field changes in a way that requires it, as
+// resourceTailnetKeyDiff does.
+func headscaleTailnetKeyDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	old, new := d.GetChange("recreate_if_invalid")
+	if old == new || !shouldRecreateIfInvalid(d.Get("reusable").(bool), d.Get("recreate_if_invalid").(string)) {
+		return nil
+	}
+
+	key, err := headscaleGetPreAuthKey(ctx, headscaleClientFor(m), d.Get("user_id").(string), d.Id())
+	if tailscale.IsNotFound(err) || (err == nil && key.invalid(time.Now())) {
+		return d.ForceNew("recreate_if_invalid")
+	}
+	return nil
+}
+
+// headscaleTailnetKeyImport imports a key from an ID of the form
+// `user_id:id`, as Headscale lists keys by user.
+func headscaleTailnetKeyImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	userID, id, ok := strings.Cut(d.Id(), ":")
+	if !ok || userID == "" || id == "" {
+		return nil, fmt.Errorf("invalid key ID %q, expected user_id:id with the headscale backend", d.Id())
+	}
+	d.SetId(id)
+	if err := d.Set("user_id", userID); err != nil {
+		return nil, err
+	}
+
+	key, err := headscaleGetPreAuthKey(ctx, headscaleClientFor(m), userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Set("key", key.Key); err != nil {
+		return nil, err
+	}
+	if diags := headscaleTailnetKeyRead(ctx, d, m); diags.HasError() {
+		return nil, diagnosticsAsError(diags)
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,281 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// mockHeadscale is an in-memory stand-in for the REST API of a Headscale
+// server, implementing the endpoints used by the headscale backend.
+type mockHeadscale struct {
+	mu     sync.Mutex
+	users  []headscaleUser
+	keys   []headscalePreAuthKey
+	nodes  map[string]*headscaleNode
+	policy string
+}
+
+func newMockHeadscale(t *testing.T) (*mockHeadscale, string) {
+	t.Helper()
+
+	alice := headscaleUser{ID: "1", Name: "alice", Email: "alice@example.com", DisplayName: "Alice", CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	mock := &mockHeadscale{
+		users: []headscaleUser{alice},
+		nodes: map[string]*headscaleNode{
+			"7": {ID: "7", Name: "router", User: &alice, IPAddresses: []string{"100.64.0.7"}},
+		},
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	return mock, server.URL
+}
+
+func (m *mockHeadscale) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeJSON := func(status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	notFound := func(message string) {
+		writeJSON(http.StatusNotFound, map[string]any{"code": 5, "message": message})
+	}
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	stringList := func(key string) []string {
+		var out []string
+		for _, v := range body[key].([]any) {
+			out = append(out, v.(string))
+		}
+		return out
+	}
+
+	if r.Header.Get("Authorization") != "Bearer hskey-api-test" {
+		writeJSON(http.StatusUnauthorized, map[string]any{"code": 16, "message": "Unauthorized"})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	switch {
+	case r.Method == http.MethodGet && path == "user":
+		var users []headscaleUser
+		for _, u := range m.users {
+			q := r.URL.Query()
+			if (q.Has("id") && q.Get("id") == u.ID) || (q.Has("name") && q.Get("name") == u.Name) || (q.Has("email") && q.Get("email") == u.Email) {
+				users = append(users, u)
+			}
+		}
+		writeJSON(http.StatusOK, map[string]any{"users": users})
+	case r.Method == http.MethodPost && path == "preauthkey":
+		var user *headscaleUser
+		for i := range m.users {
+			if m.users[i].ID == body["user"] {
+				user = &m.users[i]
+			}
+		}
+		if user == nil {
+			notFound("user not found")
+			return
+		}
+		expiration, _ := time.Parse(time.RFC3339, body["expiration"].(string))
+		id := strconv.Itoa(len(m.keys) + 1)
+		key := headscalePreAuthKey{
+			ID:         id,
+			User:       user,
+			Key:        "hskey-auth-" + id,
+			Reusable:   body["reusable"].(bool),
+			Ephemeral:  body["ephemeral"].(bool),
+			Expiration: expiration,
+			CreatedAt:  time.Now().UTC().Truncate(time.Second),
+			ACLTags:    stringList("aclTags"),
+		}
+		m.keys = append(m.keys, key)
+		writeJSON(http.StatusOK, map[string]any{"preAuthKey": key})
+	case r.Method == http.MethodGet && path == "preauthkey":
+		var keys []headscalePreAuthKey
+		for _, k := range m.keys {
+			if k.User.ID == r.URL.Query().Get("user") {
+				keys = append(keys, k)
+			}
+		}
+		writeJSON(http.StatusOK, map[string]any{"preAuthKeys": keys})
+	case r.Method == http.MethodPost && path == "preauthkey/expire":
+		for i := range m.keys {
+			if m.keys[i].Key == body["key"] && m.keys[i].User.ID == body["user"] {
+				m.keys[i].Expiration = time.Now().UTC()
+				writeJSON(http.StatusOK, map[string]any{})
+				return
+			}
+		}
+		notFound("pre-auth key not found")
+	case path == "policy" && r.Method == http.MethodGet:
+		if m.policy == "" {
+			notFound("no policy")
+			return
+		}
+		writeJSON(http.StatusOK, map[string]any{"policy": m.policy})
+	case path == "policy" && r.Method == http.MethodPut:
+		m.policy = body["policy"].(string)
+		writeJSON(http.StatusOK, map[string]any{"policy": m.policy})
+	case strings.HasPrefix(path, "node/"):
+		parts := strings.Split(path, "/")
+		node, ok := m.nodes[parts[1]]
+		if !ok {
+			notFound("node not found")
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 2:
+		case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "tags":
+			node.ForcedTags = stringList("tags")
+		case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "approve_routes":
+			node.ApprovedRoutes = stringList("routes")
+		default:
+			notFound("not found")
+			return
+		}
+		writeJSON(http.StatusOK, map[string]any{"node": node})
+	default:
+		notFound("not found")
+	}
+}
+
+func testHeadscaleProviderConfig(baseURL string) string {
+	return fmt.Sprintf(`
+		provider "tailscale" {
+			backend  = "headscale"
+			base_url = %q
+			api_key  = "hskey-api-test"
+		}`, baseURL)
+}
+
+func TestProvider_HeadscaleBackend(t *testing.T) {
+	mock, baseURL := newMockHeadscale(t)
+	config := testHeadscaleProviderConfig(baseURL) + `
+		resource "tailscale_tailnet_key" "router" {
+			user_id  = "1"
+			reusable = true
+			tags     = ["tag:router"]
+		}
+
+		resource "tailscale_device_tags" "router" {
+			device_id = "7"
+			tags      = ["tag:router"]
+		}
+
+		resource "tailscale_device_subnet_routes" "router" {
+			device_id = "7"
+			routes    = ["10.0.0.0/24"]
+		}
+
+		resource "tailscale_acl" "policy" {
+			acl = jsonencode({
+				acls = [{ action = "accept", src = ["*"], dst = ["*:*"] }]
+			})
+		}
+
+		data "tailscale_user" "alice" {
+			login_name = "alice@example.com"
+		}`
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			mock.mu.Lock()
+			defer mock.mu.Unlock()
+			if !mock.keys[0].Expiration.Before(time.Now()) {
+				return fmt.Errorf("expected the key to be expired")
+			}
+			if node := mock.nodes["7"]; len(node.ForcedTags) > 0 || len(node.ApprovedRoutes) > 0 {
+				return fmt.Errorf("expected the tags and routes of the node to be removed, got %v and %v", node.ForcedTags, node.ApprovedRoutes)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("tailscale_tailnet_key.router", "id", "1"),
+					resource.TestCheckResourceAttr("tailscale_tailnet_key.router", "key", "hskey-auth-1"),
+					resource.TestCheckResourceAttr("tailscale_tailnet_key.router", "expiry", "7776000"),
+					resource.TestCheckResourceAttr("tailscale_tailnet_key.router", "invalid", "false"),
+					resource.TestCheckResourceAttr("tailscale_device_tags.router", "tags.0", "tag:router"),
+					resource.TestCheckResourceAttr("tailscale_device_subnet_routes.router", "routes.0", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("data.tailscale_user.alice", "id", "1"),
+					resource.TestCheckResourceAttr("data.tailscale_user.alice", "display_name", "Alice"),
+					resource.TestCheckResourceAttr("data.tailscale_user.alice", "created", "2025-01-02T03:04:05Z"),
+					func(s *terraform.State) error {
+						mock.mu.Lock()
+						defer mock.mu.Unlock()
+						if !strings.Contains(mock.policy, `"dst":["*:*"]`) {
+							return fmt.Errorf("unexpected policy %s", mock.policy)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "tailscale_tailnet_key.router",
+				ImportState:       true,
+				ImportStateId:     "1:1",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestProvider_HeadscaleBackendUnsupported(t *testing.T) {
+	_, baseURL := newMockHeadscale(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testHeadscaleProviderConfig(baseURL) + `
+					resource "tailscale_dns_nameservers" "test" {
+						nameservers = ["8.8.8.8"]
+					}`,
+				ExpectError: regexp.MustCompile(`tailscale_dns_nameservers is not supported by the headscale backend`),
+			},
+			{
+				Config: testHeadscaleProviderConfig(baseURL) + `
+					resource "tailscale_tailnet_key" "test" {
+						user_id       = "1"
+						preauthorized = true
+					}`,
+				ExpectError: regexp.MustCompile(`Argument "preauthorized" of tailscale_tailnet_key is not supported by\s+the headscale backend`),
+			},
+			{
+				Config: testHeadscaleProviderConfig(baseURL) + `
+					data "tailscale_user" "test" {
+						tailnet    = "other.example.com"
+						login_name = "alice"
+					}`,
+				ExpectError: regexp.MustCompile(`Argument "tailnet" of tailscale_user is not supported by the\s+headscale backend`),
+			},
+			{
+				Config: testHeadscaleProviderConfig(baseURL) + `
+					data "tailscale_users" "test" {}`,
+				ExpectError: regexp.MustCompile(`tailscale_users is not supported by the headscale backend`),
+			},
+		},
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// headscaleUserDataSource reads tailscale_user data sources from Headscale
+// users. The login name of a Headscale user is its name, or its email for
+// users created by OIDC. Attributes that Headscale has no equivalent for,
+// such as the role and status, are empty.
+var headscaleUserDataSource = &headscaleResource{
+	read: headscaleUserDataSourceRead,
+}
+
+// headscaleListUsers returns the Headscale users matching the given filter,
+// such as `id`, `name` or `email`.
+func headscaleListUsers(ctx context.Context, hs *headscaleClient, filter, value string) ([]headscaleUser, error) {
+	var resp struct {
+		Users []headscaleUser `json:"users"`
+	}
+	if err := hs.do(ctx, http.MethodGet, "user", url.Values{filter: {value}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+func headscaleUserDataSourceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	hs := headscaleClientFor(m)
+
+	var users []headscaleUser
+	var loginName string
+	if id := d.Id(); id != "" {
+		var err error
+		if users, err = headscaleListUsers(ctx, hs, "id", id); err != nil {
+			return diagnosticsError(err, "Failed to fetch user with id %s", id)
+		}
+		if len(users) == 0 {
+			return diag.Errorf("Could not find user with id %s", id)
+		}
+	} else {
+		loginName = d.Get("login_name").(string)
+		if loginName == "" {
+			return diag.Errorf("please specify an id or login_name for the user")
+		}
+		for _, filter := range []string{"name", "email"} {
+			var err error
+			if users, err = headscaleListUsers(ctx, hs, filter, loginName); err != nil {
+				return diagnosticsError(err, "Failed to fetch users")
+			}
+			if len(users) > 0 {
+				break
+			}
+		}
+		if len(users) == 0 {
+			return diag.Errorf("Could not find user with login name %s", loginName)
+		}
+	}
+
+	user := users[0]
+	if loginName == "" {
+		loginName = cmp.Or(user.Name, user.Email)
+	}
+	d.SetId(user.ID)
+	return setProperties(d, map[string]any{
+		"id":                  user.ID,
+		"display_name":        user.DisplayName,
+		"login_name":          loginName,
+		"profile_pic_url":     user.ProfilePicURL,
+		"tailnet_id":          "",
+		"created":             user.CreatedAt.Format(time.RFC3339),
+		"type":                "",
+		"role":                "",
+		"status":              "",
+		"device_count":        0,
+		"last_seen":           "",
+		"currently_connected": false,
+	})
+}
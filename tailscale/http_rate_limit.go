@@ -0,0 +1,48 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitTransport is an http.RoundTripper that limits the rate of requests
+// with a token bucket. As the provider shares a single HTTP client between
+// all resources and data sources, the limit applies to all requests made by
+// the provider, including retries.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	reservation := t.limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		tflog.Debug(ctx, "Throttling Tailscale API request", map[string]any{
+			"method": req.Method,
+			"url":    req.URL.String(),
+			"wait":   delay.String(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			reservation.Cancel()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
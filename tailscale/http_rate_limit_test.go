@@ -0,0 +1,69 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{
+		Transport: &rateLimitTransport{
+			base:    http.DefaultTransport,
+			limiter: rate.NewLimiter(rate.Limit(20), 2),
+		},
+	}
+
+	// The first two requests use the burst, the next two wait 50ms each.
+	start := time.Now()
+	for range 4 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestRateLimitTransportContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	limiter.Allow()
+	client := &http.Client{
+		Transport: &rateLimitTransport{base: http.DefaultTransport, limiter: limiter},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The canceled request must not use up a token.
+	if limiter.Tokens() < -0.1 {
+		t.Errorf("expected the reservation to be canceled, got %f tokens", limiter.Tokens())
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	defaultMaxRetries   = 4
+	defaultRetryMinWait = time.Second
+	defaultRetryMaxWait = 30 * time.Second
+)
+
+// retryTransport is an http.RoundTripper that retries requests failing with
+// a network error, a 429 or a 5xx response, waiting with exponential backoff
+// between attempts. The Retry-After header of a response takes precedence
+// over the backoff, unless it is longer than the maximum wait, in which case
+// the response is returned without retrying.
+//
+// Responses with status 429 are retried for any method, as the request was
+// not processed. Network errors and 5xx responses are only retried for
+// idempotent requests, so that a request is never applied twice.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	minWait    time.Duration
+	maxWait    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attemptReq := req
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !shouldRetryRequest(req, resp, err) {
+			return resp, err
+		}
+
+		// The body of the request was consumed by the previous attempt, so
+		// it must be rewound. Requests whose body cannot be rewound are not
+		// retried.
+		next := req.Clone(ctx)
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			next.Body = body
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// Retrying earlier than the server asked for would most
+				// likely fail again, so give up instead.
+				if retryAfter > t.maxWait {
+					tflog.Debug(ctx, "Not retrying Tailscale API request, Retry-After exceeds the maximum wait", map[string]any{
+						"method":      req.Method,
+						"url":         req.URL.String(),
+						"retry_after": retryAfter.String(),
+						"max_wait":    t.maxWait.String(),
+					})
+					return resp, err
+				}
+				wait = retryAfter
+			}
+			// Drain the body so that the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		fields := map[string]any{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = resp.StatusCode
+		}
+		tflog.Debug(ctx, "Retrying Tailscale API request", fields)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		attemptReq = next
+	}
+}
+
+// backoff returns the time to wait before the retry following the given
+// attempt, which doubles with each attempt up to the maximum wait. A random
+// jitter of up to half the wait is applied, so that concurrent requests
+// failing together do not all retry at once.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	wait := t.minWait
+	for i := 0; i < attempt && wait < t.maxWait; i++ {
+		wait *= 2
+	}
+	wait = min(wait, t.maxWait)
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// shouldRetryRequest reports whether a request with the given outcome can
+// safely be retried.
+func shouldRetryRequest(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isIdempotentRequest(req) && !isTLSError(err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotentRequest(req)
+	}
+	return false
+}
+
+// isTLSError reports whether err is a failure to verify the certificate of
+// the server or an alert sent by the server, such as for a rejected client
+// certificate, which retrying does not fix.
+func isTLSError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var alertErr tls.AlertError
+	return errors.As(err, &certErr) || errors.As(err, &alertErr)
+}
+
+// isIdempotentRequest reports whether sending the request several times has
+// the same effect as sending it once.
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	// Like net/http, treat requests with an idempotency key as idempotent.
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
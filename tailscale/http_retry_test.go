@@ -0,0 +1,233 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+func newTestRetryClient(maxRetries int) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			base:       http.DefaultTransport,
+			maxRetries: maxRetries,
+			minWait:    time.Millisecond,
+			maxWait:    10 * time.Millisecond,
+		},
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name         string
+		method       string
+		statuses     []int
+		wantAttempts int
+		wantStatus   int
+	}{
+		{"retries 5xx for GET", http.MethodGet, []int{503, 502, 200}, 3, 200},
+		{"retries 429 for POST", http.MethodPost, []int{429, 200}, 2, 200},
+		{"does not retry 5xx for POST", http.MethodPost, []int{503, 200}, 1, 503},
+		{"does not retry 4xx", http.MethodGet, []int{404, 200}, 1, 404},
+		{"stops after max retries", http.MethodDelete, []int{500, 500, 500, 500}, 3, 500},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts int
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				w.WriteHeader(tc.statuses[attempts])
+				attempts++
+			}))
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(tc.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestRetryClient(2).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if attempts != tc.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.wantAttempts, attempts)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+			// The body must be sent again with each attempt.
+			for i, body := range bodies {
+				if body != "payload" {
+					t.Errorf("attempt %d: expected body %q, got %q", i, "payload", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var attempts int
+	var first time.Time
+	var waited time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		waited = time.Since(first)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestRetryClient(1)
+	client.Transport.(*retryTransport).maxWait = time.Minute
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if waited < time.Second {
+		t.Errorf("expected the retry to wait for the Retry-After header, waited %s", waited)
+	}
+}
+
+func TestRetryTransportRetryAfterExceedsMaxWait(t *testing.T) {
+	t.Parallel()
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	start := time.Now()
+	resp, err := newTestRetryClient(1).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got %d", http.StatusTooManyRequests, resp.StatusCode)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the request not to wait for the Retry-After header, took %s", elapsed)
+	}
+}
+
+func TestRetryTransportContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestRetryClient(1)
+	client.Transport.(*retryTransport).maxWait = 2 * time.Hour
+	start := time.Now()
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the retry to be canceled with the context, took %s", elapsed)
+	}
+}
+
+func TestRetryTransportClient(t *testing.T) {
+	t.Parallel()
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"devices": []}`))
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &tailscale.Client{
+		BaseURL: baseURL,
+		APIKey:  "not-a-real-key",
+		Tailnet: "example.com",
+		HTTP:    newTestRetryClient(2),
+	}
+
+	if _, err := client.Devices().List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tcs := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"Wed, 01 Jan 2025 00:00:10 GMT", 10 * time.Second, true},
+		{"Tue, 31 Dec 2024 23:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tc := range tcs {
+		got, ok := parseRetryAfter(tc.value, now)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %s, %t; want %s, %t", tc.value, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// httpTraceFileEnvVar is the environment variable setting the path of the
+// HAR file that API requests are recorded to.
+const httpTraceFileEnvVar = "TAILSCALE_HTTP_TRACE_FILE"
+
+// requestIDHeader is the response header identifying an API request, which
+// Tailscale support can use to look up the request.
+const requestIDHeader = "X-Request-Id"
+
+// redacted replaces secrets in traced requests and responses.
+const redacted = "REDACTED"
+
+// redactedHeaders are the headers whose values are always redacted.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// redactedFields are the JSON fields and form values, in lower case, whose
+// values are redacted from bodies. They cover auth keys, OAuth client
+// secrets and tokens, webhook secrets and log streaming credentials.
+var redactedFields = map[string]bool{
+	"key":               true,
+	"secret":            true,
+	"token":             true,
+	"access_token":      true,
+	"refresh_token":     true,
+	"id_token":          true,
+	"jwt":               true,
+	"client_secret":     true,
+	"clientsecret":      true,
+	"password":          true,
+	"s3secretaccesskey": true,
+	"gcscredentials":    true,
+}
+
+// secretPattern matches Tailscale keys and secrets, such as auth keys, API
+// keys and OAuth client secrets, wherever they appear in a body.
+var secretPattern = regexp.MustCompile(`tskey-[A-Za-z0-9_-]+`)
+
+// traceTransport is an http.RoundTripper that logs each API request at the
+// debug level with tflog, which Terraform shows when TF_LOG is set. Secrets
+// are redacted from the logged headers and bodies.
+type traceTransport struct {
+	base http.RoundTripper
+	// bodies includes the request and response bodies in the logs.
+	bodies bool
+	// har records requests to a HAR file, if not nil.
+	har *harRecorder
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	withBodies := t.bodies || t.har != nil
+
+	var reqBody []byte
+	if withBodies && req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if reqBody, err = readRequestBody(req); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	var respBody []byte
+	if err == nil && withBodies {
+		if respBody, err = io.ReadAll(resp.Body); err != nil {
+			resp.Body.Close()
+			resp = nil
+		} else {
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		}
+	}
+
+	fields := map[string]any{
+		"method":     req.Method,
+		"path":       req.URL.EscapedPath(),
+		"latency_ms": latency.Milliseconds(),
+	}
+	if req.URL.RawQuery != "" {
+		fields["query"] = req.URL.RawQuery
+	}
+	if t.bodies && reqBody != nil {
+		fields["request_body"] = redactBody(req.Header.Get("Content-Type"), reqBody)
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "Tailscale API request failed", fields)
+	} else {
+		fields["status"] = resp.StatusCode
+		if id := resp.Header.Get(requestIDHeader); id != "" {
+			fields["request_id"] = id
+		}
+		if t.bodies {
+			fields["response_body"] = redactBody(resp.Header.Get("Content-Type"), respBody)
+		}
+		tflog.Debug(ctx, "Tailscale API request", fields)
+	}
+
+	if t.har != nil {
+		t.har.record(start, latency, req, reqBody, resp, respBody, err)
+	}
+	return resp, err
+}
+
+// readRequestBody returns the body of the request, leaving the request with
+// an unread copy of it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// redactHeaders returns the headers with the values of redactedHeaders
+// replaced.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{redacted}
+		}
+	}
+	return out
+}
+
+// redactBody returns the body with the values of redactedFields and any
+// Tailscale keys replaced.
+func redactBody(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for name := range values {
+				if redactedFields[strings.ToLower(name)] {
+					values[name] = []string{redacted}
+				}
+			}
+			body = []byte(values.Encode())
+		}
+	case json.Valid(body):
+		var v any
+		if err := json.Unmarshal(body, &v); err == nil {
+			if redactedBody, err := json.Marshal(redactJSON(v)); err == nil {
+				body = redactedBody
+			}
+		}
+	}
+	return secretPattern.ReplaceAllString(string(body), redacted)
+}
+
+// redactJSON replaces the values of redactedFields in a decoded JSON value.
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			if redactedFields[strings.ToLower(name)] && value != nil {
+				v[name] = redacted
+			} else {
+				v[name] = redactJSON(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
+
+// harRecorders holds the HAR recorder of each trace file, so that all
+// provider instances in a process record to the same file.
+var harRecorders sync.Map
+
+// harRecorder records requests to a file in the HAR format, see
+// http://www.softwareishard.com/blog/har-12-spec/. Each request is written
+// over the end of the file, followed by the end of the file again, so that
+// the file is valid even if the provider exits unexpectedly.
+type harRecorder struct {
+	path string
+
+	mu sync.Mutex
+	// size is the size of the file without harLogEnd, or 0 if the file has
+	// not been written by the recorder yet.
+	size int64
+}
+
+// harLogEnd closes the entries and the log of the HAR file.
+const harLogEnd = "\n]}}\n"
+
+// harRecorderFor returns the recorder for the given file.
+func harRecorderFor(path string) *harRecorder {
+	r, _ := harRecorders.LoadOrStore(path, &harRecorder{path: path})
+	return r.(*harRecorder)
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	// Error is the error of a request that got no response.
+	Error string `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for name, values := range redactHeaders(h) {
+		for _, value := range values {
+			out = append(out, harNameValue{Name: name, Value: value})
+		}
+	}
+	return out
+}
+
+// record adds a request to the HAR file. Failures to write the file are
+// logged rather than failing the request.
+func (r *harRecorder) record(start time.Time, latency time.Duration, req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, err error) {
+	ms := float64(latency) / float64(time.Millisecond)
+	u := *req.URL
+	if u.RawQuery != "" {
+		u.RawQuery = redactBody("application/x-www-form-urlencoded", []byte(u.RawQuery))
+	}
+
+	entry := harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         u.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Wait: ms},
+	}
+	for name, values := range u.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	if reqBody != nil {
+		contentType := req.Header.Get("Content-Type")
+		entry.Request.PostData = &harPostData{MimeType: contentType, Text: redactBody(contentType, reqBody)}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		contentType := resp.Header.Get("Content-Type")
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = http.StatusText(resp.StatusCode)
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = harHeaders(resp.Header)
+		entry.Response.BodySize = len(respBody)
+		entry.Response.Content = harContent{
+			Size:     len(respBody),
+			MimeType: contentType,
+			Text:     redactBody(contentType, respBody),
+		}
+	}
+
+	if err := r.write(entry); err != nil {
+		tflog.Warn(req.Context(), "Failed to write HTTP trace file", map[string]any{
+			"path":  r.path,
+			"error": err.Error(),
+		})
+	}
+}
+
+// write appends entry to the HAR file. The first entry written by the
+// recorder continues the HAR log already in the file, written by a previous
+// provider process such as that of the plan before an apply, or starts a new
+// one.
+func (r *harRecorder) write(entry harEntry) (err error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if r.size == 0 {
+		if r.size, err = harLogSize(f); err != nil {
+			return err
+		}
+	}
+	if r.size == 0 {
+		creator, err := json.Marshal(harCreator{Name: "terraform-provider-tailscale", Version: providerVersion})
+		if err != nil {
+			return err
+		}
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		data = append([]byte(`{"log": {"version": "1.2", "creator": `+string(creator)+`, "entries": [`+"\n"), data...)
+	} else {
+		data = append([]byte(",\n"), data...)
+	}
+
+	if _, err := f.WriteAt(append(data, harLogEnd...), r.size); err != nil {
+		return err
+	}
+	r.size += int64(len(data))
+	return nil
+}
+
+// harLogSize returns the size of the HAR log in f without harLogEnd, or 0 if
+// f does not end like a HAR log written by a harRecorder.
+func harLogSize(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size() - int64(len(harLogEnd))
+	if size <= 0 {
+		return 0, nil
+	}
+
+	end := make([]byte, len(harLogEnd))
+	if _, err := f.ReadAt(end, size); err != nil {
+		return 0, err
+	}
+	if string(end) != harLogEnd {
+		return 0, nil
+	}
+	return size, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// providerTransport returns the transport that API requests are sent with,
+// configured with the CA, client certificate and proxy arguments of the
+// provider.
+func providerTransport(d *schema.ResourceData) (*http.Transport, diag.Diagnostics) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Bound each attempt rather than the whole request, so that retries are
+	// not cut short.
+	transport.ResponseHeaderTimeout = time.Minute
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// The conflicts are checked here rather than in the schema, as the empty
+	// defaults of the environment variables would count as set there.
+	caCertPEM := d.Get("ca_cert_pem").(string)
+	if path := d.Get("ca_cert_file").(string); path != "" {
+		if caCertPEM != "" {
+			return nil, diag.Errorf("tailscale provider arguments 'ca_cert_pem' and 'ca_cert_file' conflict")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, diag.Errorf("tailscale provider argument 'ca_cert_file' is invalid - %s", err)
+		}
+		caCertPEM = string(data)
+	}
+	if caCertPEM != "" {
+		// The CA certificates are trusted in addition to the system ones, so
+		// that a proxy inspecting TLS traffic can be trusted without
+		// distrusting the API itself.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caCertPEM)) {
+			return nil, diag.Errorf("tailscale provider CA certificates are invalid - no PEM encoded certificate found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCert, clientKey := d.Get("client_cert").(string), d.Get("client_key").(string)
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return nil, diag.Errorf("tailscale provider arguments 'client_cert' and 'client_key' must be set together")
+		}
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, diag.Errorf("tailscale provider client certificate is invalid - %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// Only meant for local stand-ins of the API with self-signed certificates.
+	tlsConfig.InsecureSkipVerify = d.Get("insecure_skip_verify").(bool)
+	transport.TLSClientConfig = tlsConfig
+
+	if proxyURL := d.Get("proxy_url").(string); proxyURL != "" {
+		u, err := parseProxyURL(proxyURL)
+		if err != nil {
+			return nil, diag.Errorf("tailscale provider argument 'proxy_url' is invalid - %s", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	return transport, nil
+}
+
+// parseProxyURL parses the URL of an HTTP, HTTPS or SOCKS5 proxy.
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, expected http, https or socks5", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in %q", rawURL)
+	}
+	return u, nil
+}
+
+// providerOAuth is tailscale.OAuth with its token requests sent with the HTTP
+// client it is used with. tailscale.OAuth sends them with http.DefaultClient,
+// which does not use the CA, client certificate and proxy arguments of the
+// provider.
+type providerOAuth struct {
+	tailscale.OAuth
+}
+
+// HTTPClient implements tailscale.Auth.
+func (o *providerOAuth) HTTPClient(orig *http.Client, baseURL string) *http.Client {
+	return &http.Client{
+		Transport: &oauth2.Transport{
+			Base:   orig.Transport,
+			Source: oauth2.ReuseTokenSource(nil, o.tokenSource(orig, baseURL)),
+		},
+		CheckRedirect: orig.CheckRedirect,
+		Jar:           orig.Jar,
+		Timeout:       orig.Timeout,
+	}
+}
+
+// tokenSource returns a source of access tokens requested with httpClient.
+func (o *providerOAuth) tokenSource(httpClient *http.Client, baseURL string) oauth2.TokenSource {
+	config := clientcredentials.Config{
+		ClientID:     o.ClientID,
+		ClientSecret: o.ClientSecret,
+		Scopes:       o.Scopes,
+		TokenURL:     baseURL + "/api/v2/oauth/token",
+	}
+	// The context is kept to refresh the token later, so it must not be
+	// canceled.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	return config.TokenSource(ctx)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a self-signed client certificate and its key, PEM
+// encoded.
+func testCertificate(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func testAPIHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v2/tailnet/example.com/devices":
+		_, _ = w.Write([]byte(`{"devices": []}`))
+	case "/api/v2/tailnet/example.com/user-invites":
+		_, _ = w.Write([]byte(`[]`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestProviderTransportCACertAndClientCert(t *testing.T) {
+	certPEM, keyPEM := testCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(certPEM))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(testAPIHandler))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	serverCAPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	client, err := testConfigureProvider(t, map[string]string{
+		"api_key":     "tskey-api-test",
+		"tailnet":     "example.com",
+		"base_url":    server.URL,
+		"ca_cert_pem": serverCAPEM,
+		"client_cert": certPEM,
+		"client_key":  keyPEM,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Devices().List(context.Background()); err != nil {
+		t.Errorf("expected the v2 client to trust the CA and present the client certificate, got %v", err)
+	}
+	if _, err := membershipAPI(client).listUserInvites(context.Background()); err != nil {
+		t.Errorf("expected the membership API client to trust the CA and present the client certificate, got %v", err)
+	}
+
+	// Without the CA, the certificate of the server is not trusted.
+	client, err = testConfigureProvider(t, map[string]string{
+		"api_key":     "tskey-api-test",
+		"tailnet":     "example.com",
+		"base_url":    server.URL,
+		"client_cert": certPEM,
+		"client_key":  keyPEM,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Devices().List(context.Background()); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected a certificate error, got %v", err)
+	}
+}
+
+func TestProviderTransportOAuth(t *testing.T) {
+	var tokenRequests int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/oauth/token" {
+			tokenRequests++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		testAPIHandler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	serverCAPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	// The certificate of the server is only trusted with the CA, so the token
+	// request fails unless it is sent with the transport of the provider.
+	client, err := testConfigureProvider(t, map[string]string{
+		"oauth_client_id":     "client-id",
+		"oauth_client_secret": "client-secret",
+		"tailnet":             "example.com",
+		"base_url":            server.URL,
+		"ca_cert_pem":         serverCAPEM,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Devices().List(context.Background()); err != nil {
+		t.Errorf("expected the token to be requested with the CA of the provider, got %v", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenRequests)
+	}
+}
+
+func TestProviderTransportProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the request.
+		proxied = append(proxied, r.URL.Host)
+		testAPIHandler(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := testConfigureProvider(t, map[string]string{
+		"api_key":   "tskey-api-test",
+		"tailnet":   "example.com",
+		"base_url":  "http://api.example.invalid",
+		"proxy_url": proxy.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Devices().List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := membershipAPI(client).listUserInvites(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := assertEqual([]string{"api.example.invalid", "api.example.invalid"}, proxied, "wrong proxied requests"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProviderTransportInvalid(t *testing.T) {
+	tests := map[string]struct {
+		args    map[string]string
+		wantErr string
+	}{
+		"proxy scheme": {
+			args:    map[string]string{"proxy_url": "ftp://proxy.example.com"},
+			wantErr: `unsupported scheme "ftp"`,
+		},
+		"CA certificate": {
+			args:    map[string]string{"ca_cert_pem": "not a certificate"},
+			wantErr: "no PEM encoded certificate found",
+		},
+		"CA file": {
+			args:    map[string]string{"ca_cert_file": "/nonexistent/ca.pem"},
+			wantErr: "'ca_cert_file' is invalid",
+		},
+		"CA conflict": {
+			args:    map[string]string{"ca_cert_pem": "not a certificate", "ca_cert_file": "/nonexistent/ca.pem"},
+			wantErr: "'ca_cert_pem' and 'ca_cert_file' conflict",
+		},
+		"client key only": {
+			args:    map[string]string{"client_key": "not a key"},
+			wantErr: "'client_cert' and 'client_key' must be set together",
+		},
+		"client key": {
+			args:    map[string]string{"client_cert": "not a certificate", "client_key": "not a key"},
+			wantErr: "client certificate is invalid",
+		},
+	}
+	for name, tt := range tests {
+		tt.args["api_key"] = "tskey-api-test"
+		if _, err := testConfigureProvider(t, tt.args); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error %q, got %v", name, tt.wantErr, err)
+		}
+	}
+}
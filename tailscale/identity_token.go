@@ -0,0 +1,102 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// identityTokenCommandTimeout bounds the time taken by identity_token_command.
+const identityTokenCommandTimeout = time.Minute
+
+// identityTokenFunc returns the function used to get the identity token to
+// exchange for a Tailscale API token, from at most one of a static token, a
+// file and a command. It returns nil if none is set.
+//
+// The file is read and the command is run each time the function is called,
+// which happens whenever the API token expires, so that short-lived identity
+// tokens can be refreshed by the environment during long applies.
+func identityTokenFunc(token, file, command string) (func() (string, error), error) {
+	var set []string
+	for _, source := range []struct{ name, value string }{
+		{"identity_token", token},
+		{"identity_token_file", file},
+		{"identity_token_command", command},
+	} {
+		if source.value != "" {
+			set = append(set, "'"+source.name+"'")
+		}
+	}
+	if len(set) > 1 {
+		return nil, fmt.Errorf("only one of %s can be set", strings.Join(set, ", "))
+	}
+
+	switch {
+	case token != "":
+		return func() (string, error) {
+			return token, nil
+		}, nil
+	case file != "":
+		return func() (string, error) {
+			return readIdentityTokenFile(file)
+		}, nil
+	case command != "":
+		return func() (string, error) {
+			return runIdentityTokenCommand(command)
+		}, nil
+	}
+	return nil, nil
+}
+
+// readIdentityTokenFile reads an identity token from a file, such as a
+// Kubernetes projected service account token.
+func readIdentityTokenFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read identity token file: %w", err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", fmt.Errorf("identity token file %q is empty", path)
+	}
+	return token, nil
+}
+
+// runIdentityTokenCommand runs a command with the shell and returns its
+// output as the identity token.
+func runIdentityTokenCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), identityTokenCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", fmt.Errorf("identity token command failed: %w", err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("identity token command returned no token")
+	}
+	return token, nil
+}
@@ -0,0 +1,194 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Identity token providers supported by the identity_token_provider argument.
+const (
+	identityTokenProviderGitHubActions = "github_actions"
+	identityTokenProviderGitLab        = "gitlab"
+	identityTokenProviderBuildkite     = "buildkite"
+	identityTokenProviderEnv           = "env"
+	identityTokenProviderFile          = "file"
+)
+
+var identityTokenProviders = []string{
+	identityTokenProviderGitHubActions,
+	identityTokenProviderGitLab,
+	identityTokenProviderBuildkite,
+	identityTokenProviderEnv,
+	identityTokenProviderFile,
+}
+
+// gitlabIdentityTokenEnvVar is the environment variable read by the `gitlab`
+// identity token provider. It is set by declaring an ID token with this name
+// in the `id_tokens` of the job.
+const gitlabIdentityTokenEnvVar = "TAILSCALE_GITLAB_ID_TOKEN"
+
+// identityTokenProvider obtains identity tokens from the CI/CD platform the
+// provider runs in.
+type identityTokenProvider struct {
+	// name is one of identityTokenProviders.
+	name string
+	// audience is the audience requested for tokens minted on demand, which
+	// must match the audience of the federated identity.
+	audience string
+	// file is the path read by the `file` provider.
+	file string
+
+	// getenv returns the value of an environment variable.
+	getenv func(string) string
+	// http is the client used to request tokens from GitHub Actions.
+	http *http.Client
+	// buildkiteAgent is the path of the buildkite-agent binary.
+	buildkiteAgent string
+}
+
+// identityTokenProviderFunc returns the function used to get the identity
+// token from the given provider. A new token is obtained each time the
+// function is called.
+func identityTokenProviderFunc(p identityTokenProvider) (func() (string, error), error) {
+	if p.getenv == nil {
+		return nil, errors.New("getenv must be set")
+	}
+	if p.http == nil {
+		p.http = &http.Client{Timeout: time.Minute}
+	}
+	if p.buildkiteAgent == "" {
+		p.buildkiteAgent = "buildkite-agent"
+	}
+
+	switch p.name {
+	case identityTokenProviderGitHubActions:
+		return p.githubActionsToken, nil
+	case identityTokenProviderGitLab:
+		return p.gitlabToken, nil
+	case identityTokenProviderBuildkite:
+		return p.buildkiteToken, nil
+	case identityTokenProviderEnv:
+		return p.envToken, nil
+	case identityTokenProviderFile:
+		if p.file == "" {
+			return nil, errors.New("'identity_token_file' must be set when 'identity_token_provider' is \"file\"")
+		}
+		return func() (string, error) {
+			return readIdentityTokenFile(p.file)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown identity token provider %q", p.name)
+}
+
+// githubActionsToken requests a token from the GitHub Actions OIDC provider,
+// see https://docs.github.com/en/actions/concepts/security/openid-connect.
+func (p identityTokenProvider) githubActionsToken() (string, error) {
+	requestURL := p.getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := p.getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", errors.New("ACTIONS_ID_TOKEN_REQUEST_URL and ACTIONS_ID_TOKEN_REQUEST_TOKEN are not set; " +
+			"ensure the workflow runs in GitHub Actions with the `id-token: write` permission")
+	}
+
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	if p.audience != "" {
+		q := u.Query()
+		q.Set("audience", p.audience)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+requestToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request identity token from GitHub Actions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("failed to request identity token from GitHub Actions (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode identity token from GitHub Actions: %w", err)
+	}
+	if out.Value == "" {
+		return "", errors.New("GitHub Actions returned no identity token")
+	}
+	return out.Value, nil
+}
+
+// gitlabToken reads the token from the gitlabIdentityTokenEnvVar environment
+// variable, see https://docs.gitlab.com/ci/secrets/id_token_authentication/.
+func (p identityTokenProvider) gitlabToken() (string, error) {
+	token := strings.TrimSpace(p.getenv(gitlabIdentityTokenEnvVar))
+	if token == "" {
+		return "", fmt.Errorf("%s is not set; declare an ID token named %s in the `id_tokens` of the GitLab CI/CD job", gitlabIdentityTokenEnvVar, gitlabIdentityTokenEnvVar)
+	}
+	return token, nil
+}
+
+// envToken reads the token from the environment variables of the
+// identity_token argument whenever a new token is needed, rather than once
+// when the provider is configured.
+func (p identityTokenProvider) envToken() (string, error) {
+	for _, key := range identityTokenEnvVars {
+		if token := strings.TrimSpace(p.getenv(key)); token != "" {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not set", strings.Join(identityTokenEnvVars, " or "))
+}
+
+// buildkiteToken requests a token from the Buildkite agent, see
+// https://buildkite.com/docs/agent/v3/cli-oidc.
+func (p identityTokenProvider) buildkiteToken() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), identityTokenCommandTimeout)
+	defer cancel()
+
+	args := []string{"oidc", "request-token"}
+	if p.audience != "" {
+		args = append(args, "--audience", p.audience)
+	}
+	cmd := exec.CommandContext(ctx, p.buildkiteAgent, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", fmt.Errorf("failed to request identity token from Buildkite: %w", err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("Buildkite returned no identity token")
+	}
+	return token, nil
+}
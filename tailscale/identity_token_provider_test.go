@@ -0,0 +1,238 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// testIdentityToken returns an unsigned JWT that expires in an hour.
+func testIdentityToken(subject string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	payload := fmt.Sprintf(`{"sub": %q, "exp": %d}`, subject, time.Now().Add(time.Hour).Unix())
+	return encode([]byte(`{"alg": "none"}`)) + "." + encode([]byte(payload)) + ".signature"
+}
+
+func TestIdentityTokenProviderGitHubActions(t *testing.T) {
+	t.Parallel()
+
+	idToken := testIdentityToken("repo:example/infra")
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github/token":
+			// Stands in for the GitHub Actions OIDC token endpoint.
+			requests++
+			if r.Header.Get("Authorization") != "bearer request-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if got := r.URL.Query().Get("audience"); got != "api.tailscale.com/client-id" {
+				t.Errorf("expected the audience to be requested, got %q", got)
+			}
+			if got := r.URL.Query().Get("api-version"); got != "2.0" {
+				t.Errorf("expected the query of the request URL to be kept, got %q", got)
+			}
+			_, _ = fmt.Fprintf(w, `{"value": %q}`, idToken)
+		case "/api/v2/oauth/token-exchange":
+			_ = r.ParseForm()
+			if r.Form.Get("jwt") != idToken || r.Form.Get("client_id") != "client-id" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600}`))
+		case "/api/v2/tailnet/example.com/devices":
+			if r.Header.Get("Authorization") != "Bearer access-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"devices": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	env := map[string]string{
+		"ACTIONS_ID_TOKEN_REQUEST_URL":   server.URL + "/github/token?api-version=2.0",
+		"ACTIONS_ID_TOKEN_REQUEST_TOKEN": "request-token",
+	}
+	fn, err := identityTokenProviderFunc(identityTokenProvider{
+		name:     identityTokenProviderGitHubActions,
+		audience: "api.tailscale.com/client-id",
+		getenv:   func(key string) string { return env[key] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &tailscale.Client{
+		BaseURL: baseURL,
+		Tailnet: "example.com",
+		Auth: &tailscale.IdentityFederation{
+			ClientID:    "client-id",
+			IDTokenFunc: fn,
+		},
+	}
+	if _, err := client.Devices().List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 identity token request, got %d", requests)
+	}
+
+	// Outside of GitHub Actions, the request variables are not set.
+	fn, err = identityTokenProviderFunc(identityTokenProvider{
+		name:   identityTokenProviderGitHubActions,
+		getenv: func(string) string { return "" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fn(); err == nil || !strings.Contains(err.Error(), "id-token: write") {
+		t.Errorf("expected an error about the id-token permission, got %v", err)
+	}
+}
+
+func TestIdentityTokenProviderGitLab(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{gitlabIdentityTokenEnvVar: "token\n"}
+	fn, err := identityTokenProviderFunc(identityTokenProvider{
+		name:   identityTokenProviderGitLab,
+		getenv: func(key string) string { return env[key] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := fn(); err != nil || token != "token" {
+		t.Errorf("expected token, got %q, %v", token, err)
+	}
+
+	// The environment variable of identity_token is not read.
+	delete(env, gitlabIdentityTokenEnvVar)
+	env["TAILSCALE_IDENTITY_TOKEN"] = "other-token"
+	if _, err := fn(); err == nil || !strings.Contains(err.Error(), "id_tokens") {
+		t.Errorf("expected an error about the id_tokens of the job, got %v", err)
+	}
+}
+
+func TestIdentityTokenProviderEnv(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{"IDENTITY_TOKEN": "old-token\n"}
+	fn, err := identityTokenProviderFunc(identityTokenProvider{
+		name:   identityTokenProviderEnv,
+		getenv: func(key string) string { return env[key] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := fn(); err != nil || token != "old-token" {
+		t.Errorf("expected old-token, got %q, %v", token, err)
+	}
+
+	// The environment is read again for each token.
+	env["TAILSCALE_IDENTITY_TOKEN"] = "new-token"
+	if token, err := fn(); err != nil || token != "new-token" {
+		t.Errorf("expected new-token, got %q, %v", token, err)
+	}
+
+	clear(env)
+	if _, err := fn(); err == nil || !strings.Contains(err.Error(), "TAILSCALE_IDENTITY_TOKEN") {
+		t.Errorf("expected an error about TAILSCALE_IDENTITY_TOKEN, got %v", err)
+	}
+}
+
+func TestProviderIdentityTokenProviderEnv(t *testing.T) {
+	for _, env := range []string{
+		"TAILSCALE_API_KEY", "TAILSCALE_OAUTH_CLIENT_ID", "OAUTH_CLIENT_ID", "TAILSCALE_OAUTH_CLIENT_SECRET",
+		"OAUTH_CLIENT_SECRET", "IDENTITY_TOKEN", "TAILSCALE_IDENTITY_TOKEN_FILE", "TAILSCALE_IDENTITY_TOKEN_COMMAND",
+		"TAILSCALE_IDENTITY_TOKEN_PROVIDER", "TAILSCALE_PROFILE", "TAILSCALE_CONFIG_FILE",
+	} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("HOME", t.TempDir())
+	// The env provider reads the environment variable of identity_token.
+	t.Setenv("TAILSCALE_IDENTITY_TOKEN", "token")
+
+	args := map[string]string{
+		"oauth_client_id":         "client-id",
+		"identity_token_provider": identityTokenProviderEnv,
+		"tailnet":                 "example.com",
+	}
+	if _, err := testConfigureProvider(t, args); err != nil {
+		t.Errorf("expected the environment variable not to conflict with the env provider, got %v", err)
+	}
+
+	args["identity_token"] = "other-token"
+	if _, err := testConfigureProvider(t, args); err == nil || !strings.Contains(err.Error(), "conflicting") {
+		t.Errorf("expected identity_token to conflict with the env provider, got %v", err)
+	}
+
+	// Other providers conflict with the environment variable of
+	// identity_token.
+	delete(args, "identity_token")
+	args["identity_token_provider"] = identityTokenProviderGitLab
+	if _, err := testConfigureProvider(t, args); err == nil || !strings.Contains(err.Error(), "conflicting") {
+		t.Errorf("expected TAILSCALE_IDENTITY_TOKEN to conflict with the gitlab provider, got %v", err)
+	}
+}
+
+func TestIdentityTokenProviderBuildkite(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands require a POSIX shell")
+	}
+
+	// Stands in for buildkite-agent, printing a token for the requested
+	// audience.
+	agent := filepath.Join(t.TempDir(), "buildkite-agent")
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"oidc request-token --audience\" ] || exit 1\necho \"token-for-$4\"\n"
+	if err := os.WriteFile(agent, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fn, err := identityTokenProviderFunc(identityTokenProvider{
+		name:           identityTokenProviderBuildkite,
+		audience:       "example",
+		getenv:         func(string) string { return "" },
+		buildkiteAgent: agent,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := fn(); err != nil || token != "token-for-example" {
+		t.Errorf("expected token-for-example, got %q, %v", token, err)
+	}
+}
+
+func TestIdentityTokenProviderFile(t *testing.T) {
+	t.Parallel()
+
+	if _, err := identityTokenProviderFunc(identityTokenProvider{
+		name:   identityTokenProviderFile,
+		getenv: func(string) string { return "" },
+	}); err == nil || !strings.Contains(err.Error(), "identity_token_file") {
+		t.Errorf("expected an error about identity_token_file, got %v", err)
+	}
+}
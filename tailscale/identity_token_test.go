@@ -0,0 +1,84 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIdentityTokenFuncFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fn, err := identityTokenFunc("", path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := fn(); err != nil || token != "first-token" {
+		t.Fatalf("expected first-token, got %q, %v", token, err)
+	}
+
+	// The file is read again on each call, so that rotated tokens are used.
+	if err := os.WriteFile(path, []byte("second-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := fn(); err != nil || token != "second-token" {
+		t.Fatalf("expected second-token, got %q, %v", token, err)
+	}
+
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fn(); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Fatalf("expected an error for an empty file, got %v", err)
+	}
+}
+
+func TestIdentityTokenFuncCommand(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands require a POSIX shell")
+	}
+
+	counter := filepath.Join(t.TempDir(), "counter")
+	fn, err := identityTokenFunc("", "", "echo x >> "+counter+" && echo token-$(wc -l < "+counter+" | tr -d ' ')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"token-1", "token-2"} {
+		if token, err := fn(); err != nil || token != want {
+			t.Fatalf("expected %s, got %q, %v", want, token, err)
+		}
+	}
+
+	fn, err = identityTokenFunc("", "", "echo 'not logged in' >&2; exit 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fn(); err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Fatalf("expected the error to include the command output, got %v", err)
+	}
+}
+
+func TestIdentityTokenFuncConflicts(t *testing.T) {
+	t.Parallel()
+
+	if _, err := identityTokenFunc("token", "", "echo token"); err == nil || !strings.Contains(err.Error(), "'identity_token', 'identity_token_command'") {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+
+	fn, err := identityTokenFunc("", "", "")
+	if err != nil || fn != nil {
+		t.Fatalf("expected no identity token function, got %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"tailscale.com/client/tailscale/v2"
 )
@@ -22,71 +23,134 @@ type userInvite struct {
 	Role  string `json:"role"`
 }
 
-// membershipAPIClient wraps tailscale.Client to add UserInvites and user action APIs
-// that are not exposed by the v2 client. Uses the same HTTP client and auth as the
-// tailscale.Client (see research.md T001).
+// membershipAPIClient extends tailscale.Client with the user invite and user
+// management APIs, which are not exposed by the v2 client. Requests are sent
+// with the HTTP client and credentials of the tailscale.Client, and failed
+// requests return an error decoded by apiError.
 type membershipAPIClient struct {
-	*tailscale.Client
+	client *tailscale.Client
 }
 
+// membershipAPI returns the membership APIs of the given client.
 func membershipAPI(c *tailscale.Client) *membershipAPIClient {
-	return &membershipAPIClient{Client: c}
+	// Accessing a resource initializes the client, which sets its defaults and
+	// wraps its HTTP client with the OAuth or identity federation
+	// authentication if configured. Requests sent with the initialized HTTP
+	// client are authenticated as those of the v2 client, whichever
+	// credentials are used.
+	_ = c.Users()
+	return &membershipAPIClient{client: c}
 }
 
-func (m *membershipAPIClient) baseURL() *url.URL {
-	if m.Client.BaseURL != nil {
-		return m.Client.BaseURL
-	}
-	u, _ := url.Parse("https://api.tailscale.com")
-	return u
+// buildURL builds a URL to /api/v2/... from the given path elements, which
+// are escaped.
+func (m *membershipAPIClient) buildURL(pathElements ...string) *url.URL {
+	elem := []string{"/api/v2"}
+	for _, e := range pathElements {
+		elem = append(elem, url.PathEscape(e))
+	}
+	return m.client.BaseURL.JoinPath(elem...)
+}
+
+// buildTailnetURL builds a URL to /api/v2/tailnet/<tailnet>/... from the given
+// path elements.
+func (m *membershipAPIClient) buildTailnetURL(pathElements ...string) *url.URL {
+	return m.buildURL(append([]string{"tailnet", m.client.Tailnet}, pathElements...)...)
 }
 
-func (m *membershipAPIClient) do(ctx context.Context, method, rawURL string, body any) (*http.Response, error) {
-	u, err := url.Parse(rawURL)
+// do sends a request with the given JSON body, if not nil, and decodes the
+// JSON response into out, if not nil.
+func (m *membershipAPIClient) do(ctx context.Context, method string, uri *url.URL, body, out any) error {
+	var raw []byte
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri.String(), bytes.NewReader(raw))
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if m.client.UserAgent != "" {
+		req.Header.Set("User-Agent", m.client.UserAgent)
 	}
-	var bodyReader io.Reader
 	if body != nil {
-		raw, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
-		}
-		bodyReader = bytes.NewReader(raw)
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	// The API key is cleared when the client is initialized with OAuth or
+	// identity federation credentials.
+	if m.client.APIKey != "" {
+		req.SetBasicAuth(m.client.APIKey, "")
 	}
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+
+	resp, err := m.client.HTTP.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	if m.Client.UserAgent != "" {
-		req.Header.Set("User-Agent", m.Client.UserAgent)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
 	}
-	if m.Client.APIKey != "" {
-		req.SetBasicAuth(m.Client.APIKey, "")
+	if resp.StatusCode >= http.StatusBadRequest {
+		return apiError(resp.StatusCode, respBody)
 	}
-	httpClient := m.Client.HTTP
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+	if out == nil || resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil
 	}
-	return httpClient.Do(req)
+	return json.Unmarshal(respBody, out)
 }
 
-// listUserInvites returns all open user invites for the tailnet.
-func (m *membershipAPIClient) listUserInvites(ctx context.Context) ([]userInvite, error) {
-	path := fmt.Sprintf("%s/api/v2/tailnet/%s/user-invites", m.baseURL().String(), url.PathEscape(m.Client.Tailnet))
-	resp, err := m.do(ctx, http.MethodGet, path, nil)
+// apiError returns the error for a failed request with the given status and
+// response body. Bodies that are not a JSON error, such as those of a proxy in
+// front of the API, are used as the message. The status of
+// tailscale.APIError is unexported, so the error is decoded by a
+// tailscale.Client from the response replayed to it, which makes
+// tailscale.IsNotFound and tailscale.ErrorData work with it.
+func apiError(status int, body []byte) error {
+	var apiErr tailscale.APIError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+	body, err := json.Marshal(apiErr)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list user invites: %s (%d): %s", resp.Status, resp.StatusCode, string(body))
+
+	replay := &tailscale.Client{
+		HTTP: &http.Client{Transport: apiErrorTransport{status: status, body: body}},
 	}
+	_, err = replay.Devices().Get(context.Background(), "-")
+	return err
+}
+
+// apiErrorTransport responds to every request with the given status and body.
+type apiErrorTransport struct {
+	status int
+	body   []byte
+}
+
+func (t apiErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: t.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+// listUserInvites returns all open user invites for the tailnet.
+func (m *membershipAPIClient) listUserInvites(ctx context.Context) ([]userInvite, error) {
 	var list []userInvite
-	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+	if err := m.do(ctx, http.MethodGet, m.buildTailnetURL("user-invites"), nil, &list); err != nil {
 		return nil, err
 	}
 	return list, nil
@@ -95,19 +159,9 @@ func (m *membershipAPIClient) listUserInvites(ctx context.Context) ([]userInvite
 // createUserInvite creates a user invite with the given email and role.
 // API expects POST body as array of invites; we send one.
 func (m *membershipAPIClient) createUserInvite(ctx context.Context, email, role string) (*userInvite, error) {
-	path := fmt.Sprintf("%s/api/v2/tailnet/%s/user-invites", m.baseURL().String(), url.PathEscape(m.Client.Tailnet))
 	body := []map[string]string{{"email": email, "role": role}}
-	resp, err := m.do(ctx, http.MethodPost, path, body)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("create user invite: %s (%d): %s", resp.Status, resp.StatusCode, string(bodyBytes))
-	}
 	var list []userInvite
-	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+	if err := m.do(ctx, http.MethodPost, m.buildTailnetURL("user-invites"), body, &list); err != nil {
 		return nil, err
 	}
 	if len(list) == 0 {
@@ -116,78 +170,37 @@ func (m *membershipAPIClient) createUserInvite(ctx context.Context, email, role
 	return &list[0], nil
 }
 
-// deleteUserInvite deletes a user invite by ID.
+// deleteUserInvite deletes a user invite by ID. Deleting an invite that does
+// not exist succeeds.
 func (m *membershipAPIClient) deleteUserInvite(ctx context.Context, inviteID string) error {
-	path := fmt.Sprintf("%s/api/v2/user-invites/%s", m.baseURL().String(), url.PathEscape(inviteID))
-	resp, err := m.do(ctx, http.MethodDelete, path, nil)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete user invite: %s (%d): %s", resp.Status, resp.StatusCode, string(body))
-	}
-	return nil
+	return ignoreNotFound(m.do(ctx, http.MethodDelete, m.buildURL("user-invites", inviteID), nil, nil))
 }
 
 // suspendUser suspends the user by ID.
 func (m *membershipAPIClient) suspendUser(ctx context.Context, userID string) error {
-	path := fmt.Sprintf("%s/api/v2/users/%s/suspend", m.baseURL().String(), url.PathEscape(userID))
-	resp, err := m.do(ctx, http.MethodPost, path, nil)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("suspend user: %s (%d): %s", resp.Status, resp.StatusCode, string(body))
-	}
-	return nil
+	return ignoreNotFound(m.do(ctx, http.MethodPost, m.buildURL("users", userID, "suspend"), nil, nil))
 }
 
 // restoreUser restores a suspended user by ID.
 func (m *membershipAPIClient) restoreUser(ctx context.Context, userID string) error {
-	path := fmt.Sprintf("%s/api/v2/users/%s/restore", m.baseURL().String(), url.PathEscape(userID))
-	resp, err := m.do(ctx, http.MethodPost, path, nil)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("restore user: %s (%d): %s", resp.Status, resp.StatusCode, string(body))
-	}
-	return nil
+	return ignoreNotFound(m.do(ctx, http.MethodPost, m.buildURL("users", userID, "restore"), nil, nil))
 }
 
 // deleteUser removes the user from the tailnet by ID.
 func (m *membershipAPIClient) deleteUser(ctx context.Context, userID string) error {
-	path := fmt.Sprintf("%s/api/v2/users/%s/delete", m.baseURL().String(), url.PathEscape(userID))
-	resp, err := m.do(ctx, http.MethodPost, path, nil)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete user: %s (%d): %s", resp.Status, resp.StatusCode, string(body))
-	}
-	return nil
+	return ignoreNotFound(m.do(ctx, http.MethodPost, m.buildURL("users", userID, "delete"), nil, nil))
 }
 
 // updateUserRole updates the user's role (API uses POST to /users/{id}/role).
 func (m *membershipAPIClient) updateUserRole(ctx context.Context, userID, role string) error {
-	path := fmt.Sprintf("%s/api/v2/users/%s/role", m.baseURL().String(), url.PathEscape(userID))
 	body := map[string]string{"role": role}
-	resp, err := m.do(ctx, http.MethodPost, path, body)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("update user role: %s (%d): %s", resp.Status, resp.StatusCode, string(bodyBytes))
+	return ignoreNotFound(m.do(ctx, http.MethodPost, m.buildURL("users", userID, "role"), body, nil))
+}
+
+// ignoreNotFound returns nil if err is a not found error from the API.
+func ignoreNotFound(err error) error {
+	if tailscale.IsNotFound(err) {
+		return nil
 	}
-	return nil
+	return err
 }
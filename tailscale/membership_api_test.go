@@ -0,0 +1,124 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+func TestMembershipAPIOAuth(t *testing.T) {
+	t.Parallel()
+
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/oauth/token" {
+			tokenRequests++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "unauthorized"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/tailnet/example.com/user-invites":
+			_, _ = w.Write([]byte(`[{"id": "inv1", "email": "alice@example.com", "role": "member"}]`))
+		case "/api/v2/users/u1/suspend":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "user not found"}`))
+		case "/api/v2/users/u1/role":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message": "invalid role", "data": [{"user": "u1", "errors": ["cannot demote the last admin"]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &tailscale.Client{
+		BaseURL: baseURL,
+		Tailnet: "example.com",
+		Auth: &tailscale.OAuth{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+		},
+	}
+	api := membershipAPI(client)
+
+	// The first request is authenticated without any prior request of the
+	// v2 client.
+	invites, err := api.listUserInvites(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := assertEqual([]userInvite{{ID: "inv1", Email: "alice@example.com", Role: "member"}}, invites, "wrong invites"); err != nil {
+		t.Error(err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenRequests)
+	}
+
+	if err := api.do(context.Background(), http.MethodPost, api.buildURL("users", "u1", "suspend"), nil, nil); !tailscale.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if err := api.suspendUser(context.Background(), "u1"); err != nil {
+		t.Errorf("expected suspending a missing user to succeed, got %v", err)
+	}
+
+	err = api.updateUserRole(context.Background(), "u1", "member")
+	if err == nil || err.Error() != "invalid role (400)" {
+		t.Errorf("expected an API error, got %v", err)
+	}
+	want := []tailscale.APIErrorData{{User: "u1", Errors: []string{"cannot demote the last admin"}}}
+	if err := assertEqual(want, tailscale.ErrorData(err), "wrong error data"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	err := apiError(http.StatusBadRequest, []byte(`{"message": "invalid role", "data": [{"user": "u1", "errors": ["cannot demote the last admin"]}]}`))
+	if err.Error() != "invalid role (400)" {
+		t.Errorf("expected the message and status of the response, got %q", err)
+	}
+	if tailscale.IsNotFound(err) {
+		t.Error("expected a 400 error not to be a not found error")
+	}
+	want := []tailscale.APIErrorData{{User: "u1", Errors: []string{"cannot demote the last admin"}}}
+	if err := assertEqual(want, tailscale.ErrorData(fmt.Errorf("wrapped: %w", err)), "wrong error data"); err != nil {
+		t.Error(err)
+	}
+
+	// Proxies in front of the API may return plain text errors.
+	err = apiError(http.StatusNotFound, []byte("record not found\n"))
+	if !tailscale.IsNotFound(fmt.Errorf("wrapped: %w", err)) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if err.Error() != "record not found (404)" {
+		t.Errorf("expected the body as the message, got %q", err)
+	}
+	var apiErr tailscale.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "record not found" {
+		t.Errorf("expected the error to convert to a tailscale.APIError, got %#v", apiErr)
+	}
+
+	if err := apiError(http.StatusInternalServerError, nil); err.Error() != "Internal Server Error (500)" {
+		t.Errorf("expected the status text as the message, got %q", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// diffPolicies returns a human readable description of the semantic changes
+// between two policy files, one line per change. Formatting and comments are
+// ignored. Rules in array sections (such as grants and tests) are matched by
+// their contents, so a modified rule is shown as a removal and an addition.
+func diffPolicies(oldPolicy, newPolicy string) ([]string, error) {
+	oldValue, err := decodePolicyForDiff(oldPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old policy: %w", err)
+	}
+	newValue, err := decodePolicyForDiff(newPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new policy: %w", err)
+	}
+
+	var changes []string
+	diffPolicyValues(&changes, "", oldValue, newValue)
+	return changes, nil
+}
+
+func decodePolicyForDiff(policy string) (map[string]any, error) {
+	out := map[string]any{}
+	if strings.TrimSpace(policy) == "" {
+		return out, nil
+	}
+
+	standardized, err := hujson.Standardize([]byte(policy))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(standardized, &out); err != nil {
+		return nil, err
+	}
+
+	// Top-level section names are case-insensitive.
+	normalized := make(map[string]any, len(out))
+	for k, v := range out {
+		normalized[policySectionName(k)] = v
+	}
+	return normalized, nil
+}
+
+// policySectionName returns the canonical name of a top-level section of the
+// policy file.
+func policySectionName(name string) string {
+	for _, known := range knownPolicySections {
+		if strings.EqualFold(name, known) {
+			return known
+		}
+	}
+	return name
+}
+
+// knownPolicySections are the top-level sections of the policy file, see
+// https://tailscale.com/kb/1337/policy-syntax.
+var knownPolicySections = []string{
+	"acls",
+	"attrConfig",
+	"autoApprovers",
+	"defaultSrcPosture",
+	"derpMap",
+	"disableIPv4",
+	"grants",
+	"groups",
+	"hosts",
+	"ipsets",
+	"nodeAttrs",
+	"oneCGNATRoute",
+	"postures",
+	"randomizeClientPort",
+	"ssh",
+	"sshTests",
+	"tagOwners",
+	"tests",
+}
+
+func diffPolicyValues(changes *[]string, path string, oldValue, newValue any) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		*changes = append(*changes, fmt.Sprintf("+ %s: %s", path, compactJSON(newValue)))
+		return
+	case newValue == nil:
+		*changes = append(*changes, fmt.Sprintf("- %s: %s", path, compactJSON(oldValue)))
+		return
+	}
+
+	oldObject, oldIsObject := oldValue.(map[string]any)
+	newObject, newIsObject := newValue.(map[string]any)
+	if oldIsObject && newIsObject {
+		keys := make(map[string]bool)
+		for k := range oldObject {
+			keys[k] = true
+		}
+		for k := range newObject {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			diffPolicyValues(changes, policyDiffPath(path, k), oldObject[k], newObject[k])
+		}
+		return
+	}
+
+	oldArray, oldIsArray := oldValue.([]any)
+	newArray, newIsArray := newValue.([]any)
+	if oldIsArray && newIsArray {
+		removed, added := diffPolicyArrays(oldArray, newArray)
+		if len(removed) == 0 && len(added) == 0 {
+			return
+		}
+		if isStringArray(oldArray) && isStringArray(newArray) {
+			// Lists of strings (such as group members) are shown as a single
+			// change listing the added and removed entries.
+			var parts []string
+			if len(added) > 0 {
+				parts = append(parts, "added "+strings.Join(added, ", "))
+			}
+			if len(removed) > 0 {
+				parts = append(parts, "removed "+strings.Join(removed, ", "))
+			}
+			*changes = append(*changes, fmt.Sprintf("~ %s: %s", path, strings.Join(parts, "; ")))
+			return
+		}
+		for _, r := range removed {
+			*changes = append(*changes, fmt.Sprintf("- %s: %s", path, r))
+		}
+		for _, a := range added {
+			*changes = append(*changes, fmt.Sprintf("+ %s: %s", path, a))
+		}
+		return
+	}
+
+	if oldJSON, newJSON := compactJSON(oldValue), compactJSON(newValue); oldJSON != newJSON {
+		*changes = append(*changes, fmt.Sprintf("~ %s: %s -> %s", path, oldJSON, newJSON))
+	}
+}
+
+// diffPolicyArrays returns the elements only present in oldArray and the
+// elements only present in newArray, ignoring order. Duplicate elements are
+// counted. String elements are returned as-is, other elements as JSON.
+func diffPolicyArrays(oldArray, newArray []any) (removed, added []string) {
+	counts := make(map[string]int)
+	for _, v := range newArray {
+		counts[policyArrayElement(v)]++
+	}
+	for _, v := range oldArray {
+		e := policyArrayElement(v)
+		if counts[e] > 0 {
+			counts[e]--
+			continue
+		}
+		removed = append(removed, e)
+	}
+	for _, v := range newArray {
+		e := policyArrayElement(v)
+		if counts[e] > 0 {
+			counts[e]--
+			added = append(added, e)
+		}
+	}
+	return removed, added
+}
+
+func policyArrayElement(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return compactJSON(v)
+}
+
+func isStringArray(arr []any) bool {
+	for _, v := range arr {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func policyDiffPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+func compactJSON(v any) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(out)
+}
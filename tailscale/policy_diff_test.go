@@ -0,0 +1,146 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+)
+
+func TestDiffPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		oldPolicy string
+		newPolicy string
+		want      []string
+	}{
+		{
+			name:      "formatting and comments are ignored",
+			oldPolicy: `{"groups": {"group:a": ["a@example.com"]}}`,
+			newPolicy: "{\n\t// Groups.\n\t\"Groups\": {\n\t\t\"group:a\": [\"a@example.com\"],\n\t},\n}",
+		},
+		{
+			name:      "group membership",
+			oldPolicy: `{"groups": {"group:a": ["a@example.com", "b@example.com"], "group:old": []}}`,
+			newPolicy: `{"groups": {"group:a": ["a@example.com", "c@example.com"], "group:new": ["d@example.com"]}}`,
+			want: []string{
+				`~ groups["group:a"]: added c@example.com; removed b@example.com`,
+				`+ groups["group:new"]: ["d@example.com"]`,
+				`- groups["group:old"]: []`,
+			},
+		},
+		{
+			name:      "grants",
+			oldPolicy: `{"grants": [{"src": ["*"], "dst": ["*"], "ip": ["*"]}, {"src": ["group:a"], "dst": ["tag:b"], "ip": ["tcp:22"]}]}`,
+			newPolicy: `{"grants": [{"dst": ["tag:b"], "src": ["group:a"], "ip": ["tcp:22"]}, {"src": ["group:a"], "dst": ["tag:c"], "ip": ["tcp:443"]}]}`,
+			want: []string{
+				`- grants: {"dst":["*"],"ip":["*"],"src":["*"]}`,
+				`+ grants: {"dst":["tag:c"],"ip":["tcp:443"],"src":["group:a"]}`,
+			},
+		},
+		{
+			name:      "tests and scalars",
+			oldPolicy: `{"tests": [{"src": "a@example.com", "accept": ["tag:b:22"]}], "randomizeClientPort": false}`,
+			newPolicy: `{"tests": [{"src": "a@example.com", "accept": ["tag:b:22"], "deny": ["tag:c:22"]}], "randomizeClientPort": true}`,
+			want: []string{
+				`~ randomizeClientPort: false -> true`,
+				`- tests: {"accept":["tag:b:22"],"src":"a@example.com"}`,
+				`+ tests: {"accept":["tag:b:22"],"deny":["tag:c:22"],"src":"a@example.com"}`,
+			},
+		},
+		{
+			name:      "nested sections",
+			oldPolicy: `{"autoApprovers": {"routes": {"10.0.0.0/24": ["tag:a"]}}}`,
+			newPolicy: `{"autoApprovers": {"routes": {"10.0.0.0/24": ["tag:b"]}, "exitNode": ["tag:c"]}}`,
+			want: []string{
+				`+ autoApprovers["exitNode"]: ["tag:c"]`,
+				`~ autoApprovers["routes"]["10.0.0.0/24"]: added tag:b; removed tag:a`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffPolicies(tt.oldPolicy, tt.newPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := assertEqual(tt.want, got, "wrong changes"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPolicyDiffServer(t *testing.T) {
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = []byte("{}")
+
+	provider := Provider()
+	provider.SetMeta(client)
+	srv := newPolicyDiffServer(provider)
+
+	ty := provider.ResourcesMap["tailscale_acl"].CoreConfigSchema().ImpliedType()
+	value := func(attrs map[string]cty.Value) *tfprotov5.DynamicValue {
+		vals := make(map[string]cty.Value)
+		for name, attrType := range ty.AttributeTypes() {
+			vals[name] = cty.NullVal(attrType)
+		}
+		for name, v := range attrs {
+			vals[name] = v
+		}
+		b, err := msgpack.Marshal(cty.ObjectVal(vals), ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	config := map[string]cty.Value{
+		"acl": cty.StringVal(`{"groups": {"group:a": ["a@example.com", "b@example.com"]}}`),
+	}
+	state := map[string]cty.Value{
+		"id":   cty.StringVal("id"),
+		"etag": cty.StringVal("etag"),
+		"acl":  cty.StringVal(`{"groups": {"group:a": ["a@example.com"]}}`),
+	}
+	proposed := map[string]cty.Value{
+		"id":   state["id"],
+		"etag": state["etag"],
+		"acl":  config["acl"],
+	}
+
+	resp, err := srv.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "tailscale_acl",
+		PriorState:       value(state),
+		ProposedNewState: value(proposed),
+		Config:           value(config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []*tfprotov5.Diagnostic
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Fatalf("unexpected error: %s: %s", d.Summary, d.Detail)
+		}
+		warnings = append(warnings, d)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %d", len(warnings))
+	}
+	if want := `~ groups["group:a"]: added b@example.com`; !strings.Contains(warnings[0].Detail, want) {
+		t.Errorf("expected warning to contain %q, got %q", want, warnings[0].Detail)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+// policyEvaluator decides whether a source can connect to a destination
+// according to the acls and grants of a policy file, without calling the API.
+//
+// It supports users, groups, tags, host aliases, IP addresses and CIDRs,
+// `*` and `autogroup:member`, and evaluates TCP connections as policy file
+// tests do. Other autogroups and ipsets are not evaluated, so rules using them
+// never match. Device postures and via routing are not evaluated either, so
+// connections only allowed by rules using them cannot be evaluated.
+type policyEvaluator struct {
+	acl *tailscale.ACL
+}
+
+// newPolicyEvaluator parses the given HuJSON policy file.
+func newPolicyEvaluator(policy string) (*policyEvaluator, error) {
+	standardized, err := hujson.Standardize([]byte(policy))
+	if err != nil {
+		return nil, err
+	}
+
+	var acl tailscale.ACL
+	if err := json.Unmarshal(standardized, &acl); err != nil {
+		return nil, err
+	}
+
+	return &policyEvaluator{acl: &acl}, nil
+}
+
+// tests returns the tests defined in the policy file.
+func (e *policyEvaluator) tests() []tailscale.ACLTest {
+	return e.acl.Tests
+}
+
+// allowed reports whether src can connect to dst, which must be of the form
+// `host:port`.
+func (e *policyEvaluator) allowed(src, dst string) (bool, error) {
+	i := strings.LastIndex(dst, ":")
+	if i < 0 {
+		return false, fmt.Errorf("destination %q must be of the form host:port", dst)
+	}
+	host := strings.Trim(dst[:i], "[]")
+	port, err := strconv.ParseUint(dst[i+1:], 10, 16)
+	if err != nil {
+		return false, fmt.Errorf("destination %q must have a single port", dst)
+	}
+
+	// Rules with a device posture condition or via routing, which are not
+	// evaluated, only allow the connection for some devices or routes.
+	var conditional bool
+	for _, entry := range e.acl.ACLs {
+		if !e.aclEntryAllows(entry, src, host, uint16(port)) {
+			continue
+		}
+		if len(entry.SourcePosture) > 0 || len(e.acl.DefaultSourcePosture) > 0 {
+			conditional = true
+			continue
+		}
+		return true, nil
+	}
+	for _, grant := range e.acl.Grants {
+		if !e.grantAllows(grant, src, host, uint16(port)) {
+			continue
+		}
+		if len(grant.SrcPosture) > 0 || len(grant.Via) > 0 || len(e.acl.DefaultSourcePosture) > 0 {
+			conditional = true
+			continue
+		}
+		return true, nil
+	}
+
+	if conditional {
+		return false, fmt.Errorf("whether %s can connect to %s depends on device postures or via routing, which are not evaluated", src, dst)
+	}
+	return false, nil
+}
+
+// aclEntryAllows reports whether the given acls entry allows src to connect
+// to the given port of host, regardless of its device posture condition.
+func (e *policyEvaluator) aclEntryAllows(entry tailscale.ACLEntry, src, host string, port uint16) bool {
+	if entry.Action != "" && entry.Action != "accept" {
+		return false
+	}
+	sources := append(append([]string{}, entry.Source...), entry.Users...)
+	if !isTCP(entry.Protocol) || !e.matchesAny(sources, src) {
+		return false
+	}
+	destinations := append(append([]string{}, entry.Destination...), entry.Ports...)
+	for _, d := range destinations {
+		j := strings.LastIndex(d, ":")
+		if j < 0 {
+			continue
+		}
+		if e.matches(d[:j], host) && portsMatch(d[j+1:], port) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantAllows reports whether the given grant allows src to connect to the
+// given port of host, regardless of its device posture condition and via
+// routing.
+func (e *policyEvaluator) grantAllows(grant tailscale.Grant, src, host string, port uint16) bool {
+	if !e.matchesAny(grant.Source, src) || !e.matchesAny(grant.Destination, host) {
+		return false
+	}
+	for _, ip := range grant.IP {
+		proto, ports, hasProto := strings.Cut(ip, ":")
+		if !hasProto {
+			proto, ports = "", ip
+		}
+		if isTCP(proto) && portsMatch(ports, port) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *policyEvaluator) matchesAny(selectors []string, target string) bool {
+	for _, selector := range selectors {
+		if e.matches(selector, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the selector used in a rule, such as a group or a
+// CIDR, covers the target, which is a user, tag, host alias or IP address.
+func (e *policyEvaluator) matches(selector, target string) bool {
+	if selector == "*" || selector == target {
+		return true
+	}
+
+	switch {
+	case strings.HasPrefix(selector, "group:"):
+		return e.matchesAny(e.acl.Groups[selector], target)
+	case selector == "autogroup:member":
+		return strings.Contains(target, "@") && !strings.HasPrefix(target, "tag:")
+	}
+
+	selectorPrefix, ok := e.resolvePrefix(selector)
+	if !ok {
+		return false
+	}
+	targetPrefix, ok := e.resolvePrefix(target)
+	if !ok {
+		return false
+	}
+	return selectorPrefix.Bits() <= targetPrefix.Bits() && selectorPrefix.Contains(targetPrefix.Addr())
+}
+
+// resolvePrefix returns the IP range of a host alias, IP address or CIDR.
+func (e *policyEvaluator) resolvePrefix(s string) (netip.Prefix, bool) {
+	if address, ok := e.acl.Hosts[s]; ok {
+		s = address
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), true
+	}
+	return netip.Prefix{}, false
+}
+
+// isTCP reports whether a rule for the given protocol applies to TCP, which
+// is the protocol used by policy file tests.
+func isTCP(proto string) bool {
+	switch strings.ToLower(proto) {
+	case "", "*", "tcp", "6":
+		return true
+	}
+	return false
+}
+
+// portsMatch reports whether port is included in ports, which is either `*`
+// or a comma-separated list of ports and port ranges.
+func portsMatch(ports string, port uint16) bool {
+	if ports == "*" {
+		return true
+	}
+	for _, p := range strings.Split(ports, ",") {
+		lo, hi, isRange := strings.Cut(p, "-")
+		if !isRange {
+			hi = lo
+		}
+		first, errLo := strconv.ParseUint(lo, 10, 16)
+		last, errHi := strconv.ParseUint(hi, 10, 16)
+		if errLo == nil && errHi == nil && uint64(port) >= first && uint64(port) <= last {
+			return true
+		}
+	}
+	return false
+}
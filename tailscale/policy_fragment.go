@@ -0,0 +1,374 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+// maxPolicyUpdateAttempts is the number of times a read-modify-write of the
+// policy file is attempted when it is concurrently modified by someone else.
+const maxPolicyUpdateAttempts = 5
+
+// errPolicyFragmentNotFound is returned when a policy file fragment does not
+// exist in the policy file.
+var errPolicyFragmentNotFound = errors.New("not found in policy file")
+
+// isPreconditionFailed reports whether err is the result of a policy file
+// ETag mismatch.
+func isPreconditionFailed(err error) bool {
+	return err != nil && strings.HasSuffix(err.Error(), "(412)")
+}
+
+// readPolicy fetches and parses the policy file.
+func readPolicy(ctx context.Context, client *tailscale.Client) (*hujson.Value, string, error) {
+	raw, err := client.PolicyFile().Raw(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	policy := raw.HuJSON
+	if strings.TrimSpace(policy) == "" {
+		policy = "{}"
+	}
+
+	value, err := hujson.Parse([]byte(policy))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	if _, ok := value.Value.(*hujson.Object); !ok {
+		return nil, "", errors.New("failed to parse policy file: not an object")
+	}
+
+	return &value, raw.ETag, nil
+}
+
+// updatePolicy performs a read-modify-write of the policy file, leaving
+// comments and the parts of the file not touched by update intact. The ETag
+// of the policy file is used to detect concurrent modifications, in which
+// case the update is retried against the latest policy file.
+func updatePolicy(ctx context.Context, client *tailscale.Client, update func(policy *hujson.Value) error) error {
+	for attempt := 1; ; attempt++ {
+		policy, etag, err := readPolicy(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		// Only format the result if the policy file was already formatted,
+		// so that an update does not reformat a hand-written file.
+		formatted := policy.Clone()
+		formatted.Format()
+		wasFormatted := bytes.Equal(formatted.Pack(), policy.Pack())
+
+		if err := update(policy); err != nil {
+			return err
+		}
+		if wasFormatted {
+			policy.Format()
+		}
+
+		err = client.PolicyFile().Set(ctx, policy.String(), etag)
+		if !isPreconditionFailed(err) || attempt == maxPolicyUpdateAttempts {
+			return err
+		}
+	}
+}
+
+// policyPointer returns a JSON pointer (RFC 6901) to the given path.
+func policyPointer(path ...string) string {
+	var sb strings.Builder
+	for _, p := range path {
+		p = strings.ReplaceAll(p, "~", "~0")
+		p = strings.ReplaceAll(p, "/", "~1")
+		sb.WriteString("/")
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+// policyMemberName returns the name of the member of obj matching name.
+// Section names in the policy file are case-insensitive, whereas group,
+// tag, host and route names are matched exactly.
+func policyMemberName(obj *hujson.Object, name string, caseInsensitive bool) (string, bool) {
+	for _, m := range obj.Members {
+		lit, ok := m.Name.Value.(hujson.Literal)
+		if !ok {
+			continue
+		}
+		s := lit.String()
+		if s == name || (caseInsensitive && strings.EqualFold(s, name)) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// resolvePolicyPath resolves each element of path to the name actually used
+// in the policy file. The first len(sections) elements are matched case
+// insensitively. Missing objects along the way are created when create is
+// true, otherwise errPolicyFragmentNotFound is returned.
+func resolvePolicyPath(policy *hujson.Value, create bool, sections []string, keys ...string) ([]string, error) {
+	var resolved []string
+	current := policy
+	path := append(append([]string{}, sections...), keys...)
+	for i, name := range path {
+		obj, ok := current.Value.(*hujson.Object)
+		if !ok {
+			return nil, fmt.Errorf("policy file element %q is not an object", policyPointer(resolved...))
+		}
+
+		actual, ok := policyMemberName(obj, name, i < len(sections))
+		if !ok {
+			if !create {
+				return nil, errPolicyFragmentNotFound
+			}
+			actual = name
+			if i < len(path)-1 {
+				if err := patchPolicy(policy, "add", policyPointer(append(resolved, actual)...), map[string]any{}); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		resolved = append(resolved, actual)
+		if i < len(path)-1 {
+			current = policy.Find(policyPointer(resolved...))
+		}
+	}
+	return resolved, nil
+}
+
+// getPolicyValue decodes the value at the given path of the policy file into
+// out.
+func getPolicyValue(policy *hujson.Value, out any, sections []string, keys ...string) error {
+	path, err := resolvePolicyPath(policy, false, sections, keys...)
+	if err != nil {
+		return err
+	}
+	return decodePolicyValue(*policy.Find(policyPointer(path...)), out)
+}
+
+// setPolicyValue sets the value at the given path of the policy file,
+// creating any missing sections.
+func setPolicyValue(policy *hujson.Value, value any, sections []string, keys ...string) error {
+	path, err := resolvePolicyPath(policy, true, sections, keys...)
+	if err != nil {
+		return err
+	}
+	return patchPolicy(policy, "add", policyPointer(path...), value)
+}
+
+// createPolicyValue sets the value at the given path of the policy file like
+// setPolicyValue, but fails if the value already exists, so that creating a
+// resource does not silently take over an entry that is not in its state.
+func createPolicyValue(policy *hujson.Value, value any, resourceType, importID string, sections []string, keys ...string) error {
+	path, err := resolvePolicyPath(policy, false, sections, keys...)
+	if err == nil {
+		return fmt.Errorf(
+			"! %q already exists in the policy file.\n"+
+				"Before managing it with a %s resource, please import it into Terraform state using:\n"+
+				" terraform import $(this_resource) %s", policyPointer(path...), resourceType, importID)
+	} else if !errors.Is(err, errPolicyFragmentNotFound) {
+		return err
+	}
+	return setPolicyValue(policy, value, sections, keys...)
+}
+
+// policyImportID returns the import ID of the resource d managing the policy
+// file entry with the given ID, including the tailnet of d if it is set.
+func policyImportID(d *schema.ResourceData, id string) string {
+	if tailnet, ok := d.GetOk("tailnet"); ok {
+		return tailnetID(tailnet.(string), id)
+	}
+	return id
+}
+
+// removePolicyValue removes the value at the given path of the policy file.
+// It is not an error if the value does not exist.
+func removePolicyValue(policy *hujson.Value, sections []string, keys ...string) error {
+	path, err := resolvePolicyPath(policy, false, sections, keys...)
+	if errors.Is(err, errPolicyFragmentNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return patchPolicy(policy, "remove", policyPointer(path...), nil)
+}
+
+// findPolicyRule returns the name of the given array section as used in the
+// policy file and the index of its first element that is equivalent to rule,
+// or -1 if there is none.
+func findPolicyRule[T any](policy *hujson.Value, section string, rule T) (string, int, error) {
+	path, err := resolvePolicyPath(policy, false, []string{section})
+	if errors.Is(err, errPolicyFragmentNotFound) {
+		return section, -1, nil
+	} else if err != nil {
+		return "", -1, err
+	}
+
+	arr, ok := policy.Find(policyPointer(path...)).Value.(*hujson.Array)
+	if !ok {
+		return "", -1, fmt.Errorf("policy file section %q is not an array", path[0])
+	}
+
+	want, err := json.Marshal(rule)
+	if err != nil {
+		return "", -1, err
+	}
+	for i, elem := range arr.Elements {
+		var candidate T
+		if err := decodePolicyValue(elem, &candidate); err != nil {
+			// Elements that cannot be decoded can never match.
+			continue
+		}
+		got, err := json.Marshal(candidate)
+		if err != nil {
+			return "", -1, err
+		}
+		if bytes.Equal(got, want) {
+			return path[0], i, nil
+		}
+	}
+	return path[0], -1, nil
+}
+
+// getPolicyRule decodes the element at the given index of the given array
+// section of the policy file into out.
+func getPolicyRule(policy *hujson.Value, section string, index int, out any) error {
+	path, err := resolvePolicyPath(policy, false, []string{section})
+	if err != nil {
+		return err
+	}
+
+	arr, ok := policy.Find(policyPointer(path...)).Value.(*hujson.Array)
+	if !ok {
+		return fmt.Errorf("policy file section %q is not an array", path[0])
+	}
+	if index < 0 || index >= len(arr.Elements) {
+		return errPolicyFragmentNotFound
+	}
+	return decodePolicyValue(arr.Elements[index], out)
+}
+
+// policyRuleID returns the ID of a resource managing the given rule, derived
+// from its contents, so that it is the same when the rule is imported.
+func policyRuleID(rule any) (string, error) {
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// importPolicyRule decodes the element of the given array section of the
+// policy file at the index given as the import ID of d into out, and sets the
+// ID of d to the ID derived from its contents.
+func importPolicyRule(ctx context.Context, d *schema.ResourceData, client *tailscale.Client, section string, out any) error {
+	index, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return fmt.Errorf("expected the index of the rule in the %q section of the policy file, got %q", section, d.Id())
+	}
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return err
+	}
+	if err := getPolicyRule(policy, section, index, out); errors.Is(err, errPolicyFragmentNotFound) {
+		return fmt.Errorf("rule %d not found in the %q section of the policy file", index, section)
+	} else if err != nil {
+		return err
+	}
+
+	id, err := policyRuleID(out)
+	if err != nil {
+		return err
+	}
+	d.SetId(id)
+	return nil
+}
+
+// addPolicyRule appends rule to the given array section of the policy file,
+// creating the section if necessary.
+func addPolicyRule(policy *hujson.Value, section string, rule any) error {
+	path, err := resolvePolicyPath(policy, true, []string{section})
+	if err != nil {
+		return err
+	}
+	if policy.Find(policyPointer(path...)) == nil {
+		return patchPolicy(policy, "add", policyPointer(path...), []any{rule})
+	}
+	return patchPolicy(policy, "add", policyPointer(path...)+"/-", rule)
+}
+
+// createPolicyRule appends rule to the given array section of the policy file
+// like addPolicyRule, but fails if an equivalent rule already exists. Rules are
+// identified by their contents, so the resource d creating the rule would
+// otherwise share it with the resource or person managing the existing rule.
+func createPolicyRule[T any](d *schema.ResourceData, policy *hujson.Value, section string, rule T, resourceType string) error {
+	name, index, err := findPolicyRule(policy, section, rule)
+	if err != nil {
+		return err
+	}
+	if index >= 0 {
+		return fmt.Errorf(
+			"! an equivalent rule already exists at %q in the policy file.\n"+
+				"Before managing it with a %s resource, please import it into Terraform state using:\n"+
+				" terraform import $(this_resource) %s", policyPointer(name, strconv.Itoa(index)), resourceType, policyImportID(d, strconv.Itoa(index)))
+	}
+	return addPolicyRule(policy, section, rule)
+}
+
+// removePolicyRule removes the first element of the given array section of
+// the policy file that is equivalent to rule. It is not an error if there is
+// no such element.
+func removePolicyRule[T any](policy *hujson.Value, section string, rule T) error {
+	name, index, err := findPolicyRule(policy, section, rule)
+	if err != nil || index < 0 {
+		return err
+	}
+	return patchPolicy(policy, "remove", fmt.Sprintf("%s/%d", policyPointer(name), index), nil)
+}
+
+func patchPolicy(policy *hujson.Value, op, path string, value any) error {
+	operation := map[string]any{"op": op, "path": path}
+	if op != "remove" {
+		operation["value"] = value
+	}
+	patch, err := json.Marshal([]any{operation})
+	if err != nil {
+		return err
+	}
+	return policy.Patch(patch)
+}
+
+func decodePolicyValue(value hujson.Value, out any) error {
+	value = value.Clone()
+	value.Standardize()
+	return json.Unmarshal(value.Pack(), out)
+}
+
+// resourceDataMap returns the values of the attributes in s, in the same
+// form as the attributes of a nested block.
+func resourceDataMap(d policyGetter, s map[string]*schema.Schema) map[string]interface{} {
+	out := make(map[string]interface{}, len(s))
+	for k := range s {
+		out[k] = d.Get(k)
+	}
+	return out
+}
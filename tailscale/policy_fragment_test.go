@@ -0,0 +1,243 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+func parseTestPolicy(t *testing.T, policy string) *hujson.Value {
+	t.Helper()
+	v, err := hujson.Parse([]byte(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &v
+}
+
+func TestSetPolicyValue(t *testing.T) {
+	t.Parallel()
+
+	policy := parseTestPolicy(t, `{
+	// Groups are owned by the security team.
+	"Groups": {
+		"group:a": ["a@example.com"], // Alice
+	},
+}`)
+
+	if err := setPolicyValue(policy, []string{"b@example.com"}, []string{"groups"}, "group:b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setPolicyValue(policy, []string{"tag:router"}, []string{"autoApprovers", "routes"}, "10.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	policy.Format()
+
+	want := `{
+	// Groups are owned by the security team.
+	"Groups": {
+		"group:a": ["a@example.com"], // Alice
+		"group:b": ["b@example.com"],
+	},
+	"autoApprovers": {"routes": {"10.0.0.0/24": ["tag:router"]}},
+}
+`
+	if err := assertEqual(want, policy.String(), "wrong policy"); err != nil {
+		t.Error(err)
+	}
+
+	var approvers []string
+	if err := getPolicyValue(policy, &approvers, []string{"AutoApprovers", "Routes"}, "10.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if err := assertEqual([]string{"tag:router"}, approvers, "wrong approvers"); err != nil {
+		t.Error(err)
+	}
+
+	// Keys other than section names are case-sensitive.
+	var members []string
+	if err := getPolicyValue(policy, &members, []string{"groups"}, "group:A"); !errors.Is(err, errPolicyFragmentNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestRemovePolicyValue(t *testing.T) {
+	t.Parallel()
+
+	policy := parseTestPolicy(t, `{
+	"hosts": {
+		// The router.
+		"router": "100.64.0.1",
+		"server": "100.64.0.2",
+	},
+}`)
+
+	if err := removePolicyValue(policy, []string{"hosts"}, "router"); err != nil {
+		t.Fatal(err)
+	}
+	if err := removePolicyValue(policy, []string{"hosts"}, "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := removePolicyValue(policy, []string{"groups"}, "group:missing"); err != nil {
+		t.Fatal(err)
+	}
+	policy.Format()
+
+	want := `{
+	"hosts": {
+		"server": "100.64.0.2",
+	},
+}
+`
+	if err := assertEqual(want, policy.String(), "wrong policy"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPolicyRules(t *testing.T) {
+	t.Parallel()
+
+	policy := parseTestPolicy(t, `{
+	"grants": [
+		// Everyone can reach the web servers.
+		{"src": ["*"], "dst": ["tag:web"], "ip": ["tcp:443"]},
+	],
+}`)
+
+	existing := tailscale.Grant{Source: []string{"*"}, Destination: []string{"tag:web"}, IP: []string{"tcp:443"}}
+	added := tailscale.Grant{Source: []string{"group:ops"}, Destination: []string{"tag:db"}, IP: []string{"*"}}
+
+	if _, index, err := findPolicyRule(policy, "Grants", existing); err != nil || index != 0 {
+		t.Fatalf("expected existing grant at index 0, got %d (%v)", index, err)
+	}
+	if err := addPolicyRule(policy, "grants", added); err != nil {
+		t.Fatal(err)
+	}
+	if _, index, err := findPolicyRule(policy, "grants", added); err != nil || index != 1 {
+		t.Fatalf("expected added grant at index 1, got %d (%v)", index, err)
+	}
+	if err := removePolicyRule(policy, "grants", existing); err != nil {
+		t.Fatal(err)
+	}
+	if _, index, err := findPolicyRule(policy, "grants", existing); err != nil || index != -1 {
+		t.Fatalf("expected removed grant to be missing, got %d (%v)", index, err)
+	}
+
+	// Rules are added to sections that don't exist yet.
+	ssh := tailscale.ACLSSH{Action: "accept", Source: []string{"autogroup:member"}, Destination: []string{"autogroup:self"}, Users: []string{"root"}}
+	if err := addPolicyRule(policy, "ssh", ssh); err != nil {
+		t.Fatal(err)
+	}
+	if _, index, err := findPolicyRule(policy, "ssh", ssh); err != nil || index != 0 {
+		t.Fatalf("expected ssh rule at index 0, got %d (%v)", index, err)
+	}
+}
+
+func TestUpdatePolicy(t *testing.T) {
+	t.Parallel()
+
+	const livePolicy = "{\n\t// Managed by several teams.\n\t\"groups\": {},\n}\n"
+
+	var attempts int
+	var gotPolicy, gotETag string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", `"etag"`)
+			_, _ = w.Write([]byte(livePolicy))
+		case http.MethodPost:
+			attempts++
+			if attempts == 1 {
+				// Simulate a concurrent modification.
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, _ = w.Write([]byte(`{"message": "precondition failed"}`))
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			gotPolicy, gotETag = string(body), r.Header.Get("If-Match")
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}
+
+	err = updatePolicy(context.Background(), client, func(policy *hujson.Value) error {
+		return setPolicyValue(policy, []string{"a@example.com"}, []string{"groups"}, "group:a")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if gotETag != `"etag"` {
+		t.Errorf("expected If-Match header to be set, got %q", gotETag)
+	}
+	want := "{\n\t// Managed by several teams.\n\t\"groups\": {\"group:a\": [\"a@example.com\"]},\n}\n"
+	if err := assertEqual(want, gotPolicy, "wrong policy"); err != nil {
+		t.Error(err)
+	}
+}
+
+// testPolicyServer is a stand-in for the policy file API that keeps the policy
+// file in memory and rejects writes with a stale ETag.
+type testPolicyServer struct {
+	policy string
+	etag   int
+	// concurrentChanges replace the policy file, one per write, just before
+	// the write is handled, as if someone else had changed it in the
+	// meantime.
+	concurrentChanges []string
+}
+
+func newTestPolicyServer(t *testing.T, policy string) (*tailscale.Client, *testPolicyServer) {
+	t.Helper()
+
+	ps := &testPolicyServer{policy: policy}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", strconv.Quote(strconv.Itoa(ps.etag)))
+			_, _ = w.Write([]byte(ps.policy))
+		case http.MethodPost:
+			if len(ps.concurrentChanges) > 0 {
+				ps.policy, ps.concurrentChanges = ps.concurrentChanges[0], ps.concurrentChanges[1:]
+				ps.etag++
+			}
+			if r.Header.Get("If-Match") != strconv.Quote(strconv.Itoa(ps.etag)) {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, _ = w.Write([]byte(`{"message": "precondition failed"}`))
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			ps.policy = string(body)
+			ps.etag++
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}, ps
+}
@@ -0,0 +1,300 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// policyLintIssue is a problem found in a policy file without calling the API.
+type policyLintIssue struct {
+	// Path is the location of the problem within the policy file, for example
+	// `grants[0].src[1]`.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// lintPolicy checks the policy file for common mistakes, without calling the
+// API. It reports unknown top-level sections, references to undefined groups,
+// tags without tag owners, duplicate host aliases and malformed CIDRs and
+// ports. It only reports problems that the API would also reject, but the API
+// remains the authority on whether a policy file is valid.
+func lintPolicy(policy string) ([]policyLintIssue, error) {
+	value, err := hujson.Parse([]byte(policy))
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := value.Value.(*hujson.Object)
+	if !ok {
+		return nil, errors.New("policy file must be a JSON object")
+	}
+
+	l := &policyLinter{}
+	for _, m := range obj.Members {
+		name := m.Name.Value.(hujson.Literal).String()
+		section := policySectionName(name)
+		if !isKnownPolicySection(section) {
+			l.report(name, "unknown policy file section %q", name)
+		}
+		if section == "hosts" {
+			l.checkDuplicateKeys(name, m.Value)
+		}
+	}
+
+	value.Standardize()
+	var raw map[string]any
+	if err := json.Unmarshal(value.Pack(), &raw); err != nil {
+		return nil, err
+	}
+	sections := make(map[string]any, len(raw))
+	for k, v := range raw {
+		sections[policySectionName(k)] = v
+	}
+
+	l.groups = objectKeys(sections["groups"])
+	l.tags = objectKeys(sections["tagOwners"])
+	l.lint(sections)
+	return l.issues, nil
+}
+
+type policyLinter struct {
+	groups map[string]bool
+	tags   map[string]bool
+	issues []policyLintIssue
+}
+
+func (l *policyLinter) report(path, format string, args ...any) {
+	l.issues = append(l.issues, policyLintIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *policyLinter) lint(sections map[string]any) {
+	for i, entry := range policyObjects(sections["acls"]) {
+		path := fmt.Sprintf("acls[%d]", i)
+		l.checkRefs(path, "src", entry, "src", "users")
+		for j, dst := range policyStrings(policyField(entry, "dst", "ports")) {
+			l.checkHostPort(fmt.Sprintf("%s.dst[%d]", path, j), dst)
+		}
+	}
+
+	for i, grant := range policyObjects(sections["grants"]) {
+		path := fmt.Sprintf("grants[%d]", i)
+		l.checkRefs(path, "src", grant, "src")
+		l.checkRefs(path, "dst", grant, "dst")
+		l.checkRefs(path, "via", grant, "via")
+		for j, ip := range policyStrings(policyField(grant, "ip")) {
+			// Either `*`, or ports with an optional protocol, such as `tcp:443`.
+			ports := ip
+			if idx := strings.LastIndex(ip, ":"); idx >= 0 {
+				ports = ip[idx+1:]
+			}
+			l.checkPorts(fmt.Sprintf("%s.ip[%d]", path, j), ports)
+		}
+	}
+
+	for i, rule := range policyObjects(sections["ssh"]) {
+		path := fmt.Sprintf("ssh[%d]", i)
+		l.checkRefs(path, "src", rule, "src")
+		l.checkRefs(path, "dst", rule, "dst")
+	}
+
+	if owners, ok := sections["tagOwners"].(map[string]any); ok {
+		for _, tag := range sortedKeys(owners) {
+			for j, owner := range policyStrings(owners[tag]) {
+				l.checkRef(fmt.Sprintf("tagOwners[%q][%d]", tag, j), owner)
+			}
+		}
+	}
+
+	if hosts, ok := sections["hosts"].(map[string]any); ok {
+		for _, name := range sortedKeys(hosts) {
+			path := fmt.Sprintf("hosts[%q]", name)
+			address, _ := hosts[name].(string)
+			if _, err := netip.ParseAddr(address); err == nil {
+				continue
+			}
+			if _, err := netip.ParsePrefix(address); err != nil {
+				l.report(path, "host %q must be an IP address or CIDR, got %q", name, address)
+			}
+		}
+	}
+
+	if approvers, ok := sections["autoApprovers"].(map[string]any); ok {
+		if routes, ok := policyField(approvers, "routes").(map[string]any); ok {
+			for _, route := range sortedKeys(routes) {
+				path := fmt.Sprintf("autoApprovers.routes[%q]", route)
+				if _, err := netip.ParsePrefix(route); err != nil {
+					l.report(path, "route %q is not a valid CIDR", route)
+				}
+				for j, approver := range policyStrings(routes[route]) {
+					l.checkRef(fmt.Sprintf("%s[%d]", path, j), approver)
+				}
+			}
+		}
+		for j, approver := range policyStrings(policyField(approvers, "exitNode")) {
+			l.checkRef(fmt.Sprintf("autoApprovers.exitNode[%d]", j), approver)
+		}
+	}
+
+	for i, test := range policyObjects(sections["tests"]) {
+		path := fmt.Sprintf("tests[%d]", i)
+		if src, ok := policyField(test, "src", "user").(string); ok {
+			l.checkRef(path+".src", src)
+		}
+		for j, dst := range policyStrings(policyField(test, "accept", "allow")) {
+			l.checkHostPort(fmt.Sprintf("%s.accept[%d]", path, j), dst)
+		}
+		for j, dst := range policyStrings(policyField(test, "deny")) {
+			l.checkHostPort(fmt.Sprintf("%s.deny[%d]", path, j), dst)
+		}
+	}
+}
+
+// checkRefs checks the references in the first of the given fields of obj
+// that is set.
+func (l *policyLinter) checkRefs(path, name string, obj map[string]any, fields ...string) {
+	for j, ref := range policyStrings(policyField(obj, fields...)) {
+		l.checkRef(fmt.Sprintf("%s.%s[%d]", path, name, j), ref)
+	}
+}
+
+// checkRef checks that groups and tags referenced by ref are defined. Refs
+// may be followed by a port, as in `tag:example:443`.
+func (l *policyLinter) checkRef(path, ref string) {
+	switch {
+	case strings.HasPrefix(ref, "group:"):
+		name := "group:" + strings.SplitN(strings.TrimPrefix(ref, "group:"), ":", 2)[0]
+		if !l.groups[name] {
+			l.report(path, "group %q is not defined in groups", name)
+		}
+	case strings.HasPrefix(ref, "tag:"):
+		name := "tag:" + strings.SplitN(strings.TrimPrefix(ref, "tag:"), ":", 2)[0]
+		if !l.tags[name] {
+			l.report(path, "tag %q is not defined in tagOwners", name)
+		}
+	}
+}
+
+// checkHostPort checks a `host:ports` destination.
+func (l *policyLinter) checkHostPort(path, dst string) {
+	i := strings.LastIndex(dst, ":")
+	if i < 0 {
+		l.report(path, "destination %q must be of the form host:ports", dst)
+		return
+	}
+	host, ports := dst[:i], dst[i+1:]
+
+	l.checkRef(path, host)
+	if strings.Contains(host, "/") {
+		if _, err := netip.ParsePrefix(strings.Trim(host, "[]")); err != nil {
+			l.report(path, "%q is not a valid CIDR", host)
+		}
+	}
+	l.checkPorts(path, ports)
+}
+
+// checkPorts checks a port list, which is either `*` or a comma-separated
+// list of ports and port ranges.
+func (l *policyLinter) checkPorts(path, ports string) {
+	if ports == "*" || ports == "" || !strings.ContainsAny(ports[:1], "0123456789") {
+		// Protocols without ports, such as `icmp`, are left to the API.
+		return
+	}
+	for _, p := range strings.Split(ports, ",") {
+		lo, hi, isRange := strings.Cut(p, "-")
+		if !isRange {
+			hi = lo
+		}
+		first, errLo := strconv.ParseUint(lo, 10, 16)
+		last, errHi := strconv.ParseUint(hi, 10, 16)
+		if errLo != nil || errHi != nil || first > last {
+			l.report(path, "%q is not a valid port or port range", p)
+		}
+	}
+}
+
+// checkDuplicateKeys reports keys appearing more than once in an object,
+// which are otherwise silently ignored when the policy file is parsed.
+func (l *policyLinter) checkDuplicateKeys(path string, value hujson.Value) {
+	obj, ok := value.Value.(*hujson.Object)
+	if !ok {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, m := range obj.Members {
+		name := m.Name.Value.(hujson.Literal).String()
+		if seen[name] {
+			l.report(fmt.Sprintf("%s[%q]", path, name), "duplicate host alias %q", name)
+		}
+		seen[name] = true
+	}
+}
+
+func isKnownPolicySection(name string) bool {
+	for _, known := range knownPolicySections {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// policyField returns the value of the first of the given fields set in obj.
+// Field names are case-insensitive.
+func policyField(obj map[string]any, names ...string) any {
+	for _, name := range names {
+		for k, v := range obj {
+			if strings.EqualFold(k, name) {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+func policyObjects(v any) []map[string]any {
+	items, _ := v.([]any)
+	out := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		obj, _ := item.(map[string]any)
+		out = append(out, obj)
+	}
+	return out
+}
+
+func policyStrings(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, _ := item.(string)
+		out = append(out, s)
+	}
+	return out
+}
+
+func objectKeys(v any) map[string]bool {
+	obj, _ := v.(map[string]any)
+	out := make(map[string]bool, len(obj))
+	for k := range obj {
+		out[k] = true
+	}
+	return out
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,114 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestLintPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy string
+		want   []policyLintIssue
+	}{
+		{
+			name: "valid policy",
+			policy: `{
+				// Comments are allowed.
+				"Groups": {"group:eng": ["alice@example.com"]},
+				"TagOwners": {"tag:web": ["group:eng"], "tag:router": ["autogroup:admin"]},
+				"Hosts": {"db": "100.64.0.1", "office": "10.0.0.0/24"},
+				"ACLs": [{"Action": "accept", "Users": ["group:eng"], "Ports": ["tag:web:80,443", "db:5432", "10.0.0.0/24:*"]}],
+				"grants": [{"src": ["group:eng"], "dst": ["tag:web"], "ip": ["tcp:443", "icmp", "*", "8000-8080"]}],
+				"ssh": [{"action": "check", "src": ["autogroup:member"], "dst": ["autogroup:self"], "users": ["root"]}],
+				"autoApprovers": {"routes": {"10.0.0.0/24": ["tag:router"]}, "exitNode": ["tag:router"]},
+				"tests": [{"src": "alice@example.com", "accept": ["tag:web:443"], "deny": ["db:22"]}],
+			}`,
+		},
+		{
+			name:   "unknown section",
+			policy: `{"group": {}, "Hosts": {}}`,
+			want: []policyLintIssue{
+				{Path: "group", Message: `unknown policy file section "group"`},
+			},
+		},
+		{
+			name: "undefined groups and tags",
+			policy: `{
+				"groups": {"group:eng": []},
+				"tagOwners": {"tag:web": ["group:ops"]},
+				"grants": [{"src": ["group:eng", "group:sales"], "dst": ["tag:web", "tag:db"], "ip": ["*"]}],
+				"acls": [{"action": "accept", "src": ["*"], "dst": ["tag:mail:25"]}],
+			}`,
+			want: []policyLintIssue{
+				{Path: "acls[0].dst[0]", Message: `tag "tag:mail" is not defined in tagOwners`},
+				{Path: "grants[0].src[1]", Message: `group "group:sales" is not defined in groups`},
+				{Path: "grants[0].dst[1]", Message: `tag "tag:db" is not defined in tagOwners`},
+				{Path: `tagOwners["tag:web"][0]`, Message: `group "group:ops" is not defined in groups`},
+			},
+		},
+		{
+			name:   "duplicate hosts",
+			policy: `{"hosts": {"db": "100.64.0.1", "db": "100.64.0.2"}}`,
+			want: []policyLintIssue{
+				{Path: `hosts["db"]`, Message: `duplicate host alias "db"`},
+			},
+		},
+		{
+			name: "malformed CIDRs and ports",
+			policy: `{
+				"hosts": {"db": "100.64.0.300", "net": "10.0.0.0/33"},
+				"acls": [{"action": "accept", "src": ["*"], "dst": ["db:70000", "10.0.0.0/99:22", "db:90-80", "db"]}],
+				"grants": [{"src": ["*"], "dst": ["*"], "ip": ["tcp:1x"]}],
+				"autoApprovers": {"routes": {"10.0.0.0": []}},
+			}`,
+			want: []policyLintIssue{
+				{Path: "acls[0].dst[0]", Message: `"70000" is not a valid port or port range`},
+				{Path: "acls[0].dst[1]", Message: `"10.0.0.0/99" is not a valid CIDR`},
+				{Path: "acls[0].dst[2]", Message: `"90-80" is not a valid port or port range`},
+				{Path: "acls[0].dst[3]", Message: `destination "db" must be of the form host:ports`},
+				{Path: "grants[0].ip[0]", Message: `"1x" is not a valid port or port range`},
+				{Path: `hosts["db"]`, Message: `host "db" must be an IP address or CIDR, got "100.64.0.300"`},
+				{Path: `hosts["net"]`, Message: `host "net" must be an IP address or CIDR, got "10.0.0.0/33"`},
+				{Path: `autoApprovers.routes["10.0.0.0"]`, Message: `route "10.0.0.0" is not a valid CIDR`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lintPolicy(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := assertEqual(tt.want, got, "wrong issues"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyHuJSON(t *testing.T) {
+	t.Parallel()
+
+	path := cty.GetAttrPath("acl")
+	diags := validatePolicyHuJSON(`{"grants": [{"src": ["group:a"], "dst": ["tag:b"], "ip": ["*"]}]}`, path)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d: %v", len(diags), diags)
+	}
+	for _, d := range diags {
+		if !d.AttributePath.Equals(path) {
+			t.Errorf("expected diagnostic for %v, got %v", path, d.AttributePath)
+		}
+	}
+
+	if diags := validatePolicyHuJSON(`{"grants": [`, path); !diags.HasError() {
+		t.Error("expected invalid HuJSON to be reported")
+	}
+}
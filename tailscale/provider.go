@@ -6,10 +6,12 @@
 package tailscale
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"maps"
+	"net/http"
 	"net/url"
 	"os"
 	"time"
@@ -18,6 +20,8 @@ import (
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/time/rate"
 
 	"tailscale.com/client/tailscale/v2"
 )
@@ -27,12 +31,15 @@ var providerVersion = "dev"
 
 type ProviderOption func(p *schema.Provider)
 
+// Support both sets of OAuth Env vars for backwards compatibility
+var (
+	oauthClientIDEnvVars     = []string{"TAILSCALE_OAUTH_CLIENT_ID", "OAUTH_CLIENT_ID"}
+	oauthClientSecretEnvVars = []string{"TAILSCALE_OAUTH_CLIENT_SECRET", "OAUTH_CLIENT_SECRET"}
+	identityTokenEnvVars     = []string{"TAILSCALE_IDENTITY_TOKEN", "IDENTITY_TOKEN"}
+)
+
 // Provider returns the *schema.Provider instance that implements the terraform provider.
 func Provider(options ...ProviderOption) *schema.Provider {
-	// Support both sets of OAuth Env vars for backwards compatibility
-	oauthClientIDEnvVars := []string{"TAILSCALE_OAUTH_CLIENT_ID", "OAUTH_CLIENT_ID"}
-	oauthClientSecretEnvVars := []string{"TAILSCALE_OAUTH_CLIENT_SECRET", "OAUTH_CLIENT_SECRET"}
-	identityTokenEnvVars := []string{"TAILSCALE_IDENTITY_TOKEN", "IDENTITY_TOKEN"}
 
 	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
@@ -50,6 +57,31 @@ func Provider(options ...ProviderOption) *schema.Provider {
 				Description: "The jwt identity token to exchange for a Tailscale API token when using a federated identity. Can be set via the TAILSCALE_IDENTITY_TOKEN environment variable. Conflicts with 'api_key' and 'oauth_client_secret'.",
 				Sensitive:   true,
 			},
+			"identity_token_file": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_IDENTITY_TOKEN_FILE", ""),
+				Optional:    true,
+				Description: "The path to a file containing the jwt identity token to exchange for a Tailscale API token when using a federated identity, such as a Kubernetes projected service account token. The file is read again whenever a new API token is needed, so that the identity token can be refreshed during long applies. Can be set via the TAILSCALE_IDENTITY_TOKEN_FILE environment variable. Conflicts with 'api_key', 'oauth_client_secret', 'identity_token' and 'identity_token_command'.",
+			},
+			"identity_token_command": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_IDENTITY_TOKEN_COMMAND", ""),
+				Optional:    true,
+				Description: "A command printing the jwt identity token to exchange for a Tailscale API token when using a federated identity. The command is run with the system shell whenever a new API token is needed, so that the identity token can be refreshed during long applies. Can be set via the TAILSCALE_IDENTITY_TOKEN_COMMAND environment variable. Conflicts with 'api_key', 'oauth_client_secret', 'identity_token' and 'identity_token_file'.",
+			},
+			"identity_token_provider": {
+				Type:         schema.TypeString,
+				DefaultFunc:  schema.EnvDefaultFunc("TAILSCALE_IDENTITY_TOKEN_PROVIDER", ""),
+				Optional:     true,
+				ValidateFunc: validation.Any(validation.StringIsEmpty, validation.StringInSlice(identityTokenProviders, false)),
+				Description:  "Obtains the jwt identity token to exchange for a Tailscale API token when using a federated identity from the CI/CD platform the provider runs in, instead of 'identity_token'. One of `github_actions` (requires the `id-token: write` permission), `gitlab` (reads the `" + gitlabIdentityTokenEnvVar + "` ID token declared in the `id_tokens` of the job), `buildkite` (uses `buildkite-agent oidc request-token`), `env` (reads the TAILSCALE_IDENTITY_TOKEN environment variable whenever a new identity token is needed) or `file` (reads 'identity_token_file'). A new identity token is obtained whenever a new API token is needed. Can be set via the TAILSCALE_IDENTITY_TOKEN_PROVIDER environment variable. Conflicts with 'api_key', 'oauth_client_secret', 'identity_token' and 'identity_token_command'.",
+			},
+			"identity_token_audience": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_IDENTITY_TOKEN_AUDIENCE", ""),
+				Optional:    true,
+				Description: "The audience of the identity tokens requested by the `github_actions` and `buildkite` identity token providers. It must be equal to the `audience` of the federated identity, otherwise the identity tokens are rejected. The audience is not read from the federated identity, as the provider cannot read it before it is authenticated. For the `gitlab` identity token provider, set the `aud` of the ID token in the `id_tokens` of the job instead. Can be set via the TAILSCALE_IDENTITY_TOKEN_AUDIENCE environment variable.",
+			},
 			"oauth_client_id": {
 				Type:        schema.TypeString,
 				DefaultFunc: schema.MultiEnvDefaultFunc(oauthClientIDEnvVars, ""),
@@ -81,11 +113,131 @@ func Provider(options ...ProviderOption) *schema.Provider {
 				Optional:    true,
 				Description: "The base URL of the Tailscale API. Defaults to https://api.tailscale.com. Can be set via the TAILSCALE_BASE_URL environment variable.",
 			},
+			"backend": {
+				Type:         schema.TypeString,
+				DefaultFunc:  schema.EnvDefaultFunc("TAILSCALE_BACKEND", backendTailscale),
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{backendTailscale, backendHeadscale}, false),
+				Description:  "The control server API managed by the provider. One of `tailscale` or `headscale`, which manages a self-hosted Headscale server at 'base_url' with a Headscale API key set in 'api_key'. Only some resources and data sources are supported by the `headscale` backend. Can be set via the TAILSCALE_BACKEND environment variable. Defaults to `tailscale`.",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_PROFILE", ""),
+				Optional:    true,
+				Description: "The profile of the credentials file to read 'api_key', 'oauth_client_id', 'oauth_client_secret', 'tailnet' and 'base_url' from. Arguments set in the provider configuration or through environment variables take precedence over the profile, and the credentials of the profile are not used if any credentials are set otherwise. Defaults to the `default` profile, if it exists. Can be set via the TAILSCALE_PROFILE environment variable.",
+			},
+			"config_file": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_CONFIG_FILE", ""),
+				Optional:    true,
+				Description: "The path of the credentials file containing the profiles selected by 'profile'. Defaults to `~/.config/tailscale/credentials`, if it exists. Can be set via the TAILSCALE_CONFIG_FILE environment variable.",
+			},
 			"user_agent": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "User-Agent header for API requests.",
 			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultMaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of times an API request is retried after failing with a 429 response, a 5xx response or a network error. Requests that are not idempotent are only retried after a 429 response. Set to 0 to disable retries. Defaults to 4.",
+			},
+			"retry_min_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultRetryMinWait.String(),
+				ValidateFunc: validateDuration,
+				Description:  "The time to wait before the first retry of an API request, as a duration such as `500ms` or `2s`. The wait doubles with each retry, up to 'retry_max_wait'. A Retry-After header in the response takes precedence. Defaults to `1s`.",
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultRetryMaxWait.String(),
+				ValidateFunc: validateDuration,
+				Description:  "The maximum time to wait between retries of an API request, as a duration such as `30s` or `1m`. A request is not retried if the Retry-After header of its response asks to wait longer. Defaults to `30s`.",
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "The maximum average number of API requests per second made by the provider, shared by all resources and data sources. Retries count towards the limit. Defaults to 0, which does not limit requests.",
+			},
+			"burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of API requests that can be made at once above 'requests_per_second'. Only used when 'requests_per_second' is set. Defaults to 1.",
+			},
+			"device_cache_ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "Enables the device cache, which serves device reads from a single list of all devices in the tailnet instead of making one request per device, and sets how long the list is used before it is fetched again, as a duration such as `5m`. Devices changed by the provider are always read from the API. The cache is disabled by default.",
+			},
+			"ca_cert_pem": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_CA_CERT_PEM", ""),
+				Optional:    true,
+				Description: "PEM encoded CA certificates to trust for API requests in addition to the system CA certificates, such as the CA of a proxy inspecting TLS traffic. Can be set via the TAILSCALE_CA_CERT_PEM environment variable. Conflicts with 'ca_cert_file'.",
+			},
+			"ca_cert_file": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_CA_CERT_FILE", ""),
+				Optional:    true,
+				Description: "The path to a file of PEM encoded CA certificates to trust for API requests in addition to the system CA certificates. Can be set via the TAILSCALE_CA_CERT_FILE environment variable. Conflicts with 'ca_cert_pem'.",
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_CLIENT_CERT", ""),
+				Optional:    true,
+				Description: "The PEM encoded client certificate presented for mutual TLS authentication with the API or a proxy. Can be set via the TAILSCALE_CLIENT_CERT environment variable. Must be set with 'client_key'.",
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_CLIENT_KEY", ""),
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The PEM encoded private key of 'client_cert'. Can be set via the TAILSCALE_CLIENT_KEY environment variable. Must be set with 'client_cert'.",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_PROXY_URL", ""),
+				Optional:    true,
+				Description: "The URL of the HTTP, HTTPS or SOCKS5 proxy that API requests are sent through, such as `http://proxy.example.com:3128`. Defaults to the proxy set by the HTTPS_PROXY and NO_PROXY environment variables. Can be set via the TAILSCALE_PROXY_URL environment variable.",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skips the verification of the TLS certificate of the API. Only use this with local stand-ins of the API. Defaults to false.",
+			},
+			"log_http_bodies": {
+				Type:        schema.TypeBool,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_LOG_HTTP_BODIES", false),
+				Optional:    true,
+				Description: "Includes the request and response bodies in the debug logs of API requests, which are shown when TF_LOG is set to `DEBUG` or `TRACE`. Secrets such as keys, OAuth client secrets, webhook secrets and log streaming tokens are redacted. Can be set via the TAILSCALE_LOG_HTTP_BODIES environment variable. Set the " + httpTraceFileEnvVar + " environment variable to also record API requests to a HAR file. Defaults to false.",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				DefaultFunc: schema.EnvDefaultFunc("TAILSCALE_READ_ONLY", false),
+				Optional:    true,
+				Description: "Prevents the provider from changing the tailnet, for running plans with credentials that must not be used to make changes. Resources fail to be created, updated or deleted, and API requests other than reads, access token requests and policy file validations are rejected before being sent. Can be set via the TAILSCALE_READ_ONLY environment variable. Defaults to false.",
+			},
+			"allowed_tag_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Patterns that all tags written by the `tailscale_tailnet_key`, `tailscale_device_tags`, `tailscale_oauth_client` and `tailscale_federated_identity` resources must match, such as `tag:team-*`. `*` matches any sequence of characters and `?` any single character. Plans setting other tags fail. Defaults to allowing all tags.",
+			},
+			"default_tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Tags added to the tags of all `tailscale_tailnet_key`, `tailscale_device_tags`, `tailscale_oauth_client` and `tailscale_federated_identity` resources. Must match 'allowed_tag_patterns'.",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"tailscale_acl":                     resourceACL(),
@@ -108,17 +260,44 @@ func Provider(options ...ProviderOption) *schema.Provider {
 			"tailscale_tailnet_settings":        resourceTailnetSettings(),
 			"tailscale_tailnet_membership":      resourceTailnetMembership(),
 			"tailscale_federated_identity":      resourceFederatedIdentity(),
+			"tailscale_policy":                  resourcePolicy(),
+			"tailscale_acl_auto_approver":       resourceACLAutoApprover(),
+			"tailscale_acl_grant":               resourceACLGrant(),
+			"tailscale_acl_group":               resourceACLGroup(),
+			"tailscale_acl_host":                resourceACLHost(),
+			"tailscale_acl_ssh":                 resourceACLSSH(),
+			"tailscale_acl_tag_owner":           resourceACLTagOwner(),
+			"tailscale_acl_rollback":            resourceACLRollback(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"tailscale_device":  dataSourceDevice(),
-			"tailscale_devices": dataSourceDevices(),
-			"tailscale_4via6":   dataSource4Via6(),
-			"tailscale_acl":     dataSourceACL(),
-			"tailscale_user":    dataSourceUser(),
-			"tailscale_users":   dataSourceUsers(),
+			"tailscale_device":      dataSourceDevice(),
+			"tailscale_devices":     dataSourceDevices(),
+			"tailscale_4via6":       dataSource4Via6(),
+			"tailscale_acl":         dataSourceACL(),
+			"tailscale_user":        dataSourceUser(),
+			"tailscale_users":       dataSourceUsers(),
+			"tailscale_policy_test": dataSourcePolicyTest(),
 		},
 	}
 
+	// All resources and data sources calling the API can be used with a tailnet
+	// other than the provider tailnet.
+	for name, r := range provider.ResourcesMap {
+		// The ID of tailnet memberships already carries the tailnet.
+		withTailnet(r, false, name != "tailscale_tailnet_membership")
+		withHeadscale(r, name, headscaleResources[name])
+		if taggedResources[name] {
+			withTagPolicy(r)
+		}
+		withReadOnly(r, name)
+	}
+	for name, r := range provider.DataSourcesMap {
+		if name != "tailscale_4via6" && name != "tailscale_policy_test" {
+			withTailnet(r, true, false)
+			withHeadscale(r, name, headscaleDataSources[name])
+		}
+	}
+
 	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		return providerConfigure(ctx, provider, d)
 	}
@@ -131,13 +310,27 @@ func Provider(options ...ProviderOption) *schema.Provider {
 }
 
 func providerConfigure(_ context.Context, provider *schema.Provider, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	baseURL := d.Get("base_url").(string)
+	profile, err := loadCredentialsProfile(d)
+	if err != nil {
+		return nil, diag.Errorf("failed to load tailscale provider credentials profile: %s", err)
+	}
+
+	// Arguments set in the configuration or through environment variables take
+	// precedence over the profile.
+	argument := func(key string) string {
+		if value, ok := profile[key]; ok && !isArgumentSet(d, key) {
+			return value
+		}
+		return d.Get(key).(string)
+	}
+
+	baseURL := argument("base_url")
 	parsedBaseURL, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, diag.Errorf("could not parse baseURL %q: %s", baseURL, err)
 	}
 
-	tailnet := d.Get("tailnet").(string)
+	tailnet := argument("tailnet")
 	if tailnet == "" {
 		return nil, diag.Errorf("tailscale provider argument 'tailnet' is empty")
 	}
@@ -146,8 +339,63 @@ func providerConfigure(_ context.Context, provider *schema.Provider, d *schema.R
 	oauthClientID := d.Get("oauth_client_id").(string)
 	oauthClientSecret := d.Get("oauth_client_secret").(string)
 	idToken := d.Get("identity_token").(string)
+	idTokenFile := d.Get("identity_token_file").(string)
+	idTokenCommand := d.Get("identity_token_command").(string)
+	idTokenProvider := d.Get("identity_token_provider").(string)
+
+	// The credentials of the profile are only used if no credentials are set
+	// otherwise, so that they are never mixed.
+	if cmp.Or(apiKey, oauthClientID, oauthClientSecret, idToken, idTokenFile, idTokenCommand, idTokenProvider) == "" {
+		apiKey = profile["api_key"]
+		oauthClientID = profile["oauth_client_id"]
+		oauthClientSecret = profile["oauth_client_secret"]
+	}
+
+	// Any of the identity token sources can be used alongside 'oauth_client_id'.
+	if diags := validateProviderCreds(apiKey, oauthClientID, oauthClientSecret, cmp.Or(idToken, idTokenFile, idTokenCommand, idTokenProvider)); diags != nil && diags.HasError() {
+		return nil, diags
+	}
+
+	backend := d.Get("backend").(string)
+	if backend == backendHeadscale {
+		if apiKey == "" {
+			return nil, diag.Errorf("tailscale provider backend \"headscale\" requires 'api_key' to be set to a Headscale API key")
+		}
+		if !isArgumentSet(d, "base_url") && profile["base_url"] == "" {
+			return nil, diag.Errorf("tailscale provider backend \"headscale\" requires 'base_url' to be set to the URL of the Headscale server")
+		}
+	}
+
+	// The `env` identity token provider reads the environment variables of
+	// 'identity_token', so it only conflicts with 'identity_token' if set in
+	// the configuration.
+	if idTokenProvider == identityTokenProviderEnv && !isArgumentSet(d, "identity_token") {
+		idToken = ""
+	}
+
+	var idTokenFunc func() (string, error)
+	if idTokenProvider != "" {
+		if idToken != "" || idTokenCommand != "" || (idTokenFile != "" && idTokenProvider != identityTokenProviderFile) {
+			return nil, diag.Errorf("tailscale provider credentials are conflicting - 'identity_token_provider' conflicts with 'identity_token', 'identity_token_file' and 'identity_token_command'")
+		}
+		idTokenFunc, err = identityTokenProviderFunc(identityTokenProvider{
+			name:     idTokenProvider,
+			audience: d.Get("identity_token_audience").(string),
+			file:     idTokenFile,
+			getenv:   os.Getenv,
+		})
+		if err != nil {
+			return nil, diag.Errorf("tailscale provider argument 'identity_token_provider' is invalid - %s", err)
+		}
+	} else {
+		idTokenFunc, err = identityTokenFunc(idToken, idTokenFile, idTokenCommand)
+		if err != nil {
+			return nil, diag.Errorf("tailscale provider credentials are conflicting - %s", err)
+		}
+	}
 
-	if diags := validateProviderCreds(apiKey, oauthClientID, oauthClientSecret, idToken); diags != nil && diags.HasError() {
+	httpClient, diags := providerHTTPClient(d)
+	if diags.HasError() {
 		return nil, diags
 	}
 
@@ -156,7 +404,9 @@ func providerConfigure(_ context.Context, provider *schema.Provider, d *schema.R
 		userAgent = provider.UserAgent("terraform-provider-tailscale", providerVersion)
 	}
 
-	if oauthClientID != "" && oauthClientSecret != "" {
+	var client *tailscale.Client
+	switch {
+	case oauthClientID != "" && oauthClientSecret != "":
 		var oauthScopes []string
 		oauthScopesFromConfig := d.Get("scopes").([]interface{})
 		if len(oauthScopesFromConfig) > 0 {
@@ -166,44 +416,128 @@ func providerConfigure(_ context.Context, provider *schema.Provider, d *schema.R
 			oauthScopes[i] = scope.(string)
 		}
 
-		client := &tailscale.Client{
+		client = &tailscale.Client{
 			BaseURL:   parsedBaseURL,
 			UserAgent: userAgent,
 			Tailnet:   tailnet,
-			Auth: &tailscale.OAuth{
+			HTTP:      httpClient,
+			Auth: &providerOAuth{tailscale.OAuth{
 				ClientID:     oauthClientID,
 				ClientSecret: oauthClientSecret,
 				Scopes:       oauthScopes,
-			},
+			}},
 		}
-
-		return client, nil
-	}
-
-	if oauthClientID != "" && idToken != "" {
-		return &tailscale.Client{
+	case oauthClientID != "" && idTokenFunc != nil:
+		client = &tailscale.Client{
 			BaseURL:   parsedBaseURL,
 			UserAgent: userAgent,
 			Tailnet:   tailnet,
+			HTTP:      httpClient,
 			Auth: &tailscale.IdentityFederation{
-				ClientID: oauthClientID,
-				IDTokenFunc: func() (string, error) {
-					return idToken, nil
-				},
+				ClientID:    oauthClientID,
+				IDTokenFunc: idTokenFunc,
 			},
-		}, nil
+		}
+	default:
+		client = &tailscale.Client{
+			BaseURL:   parsedBaseURL,
+			UserAgent: userAgent,
+			APIKey:    apiKey,
+			Tailnet:   tailnet,
+			HTTP:      httpClient,
+		}
+	}
+
+	state := &providerState{
+		httpClient: httpClient,
+		readOnly:   d.Get("read_only").(bool),
 	}
 
-	client := &tailscale.Client{
-		BaseURL:   parsedBaseURL,
-		UserAgent: userAgent,
-		APIKey:    apiKey,
-		Tailnet:   tailnet,
+	if backend == backendHeadscale {
+		state.headscale = &headscaleClient{
+			baseURL:   parsedBaseURL,
+			apiKey:    apiKey,
+			userAgent: userAgent,
+			http:      httpClient,
+		}
 	}
 
+	var allowedTagPatterns, defaultTags []string
+	for _, pattern := range d.Get("allowed_tag_patterns").([]interface{}) {
+		allowedTagPatterns = append(allowedTagPatterns, pattern.(string))
+	}
+	for _, tag := range d.Get("default_tags").(*schema.Set).List() {
+		defaultTags = append(defaultTags, tag.(string))
+	}
+	if len(allowedTagPatterns) > 0 || len(defaultTags) > 0 {
+		state.tagPolicy, err = newTagPolicy(allowedTagPatterns, defaultTags)
+		if err != nil {
+			return nil, diag.Errorf("tailscale provider tag settings are invalid - %s", err)
+		}
+	}
+
+	if ttl, ok := d.GetOk("device_cache_ttl"); ok {
+		// The duration is validated by the schema.
+		dur, _ := time.ParseDuration(ttl.(string))
+		if dur > 0 {
+			state.deviceCache = newDeviceCache(dur)
+		}
+	}
+
+	setProviderState(client, state)
+
 	return client, nil
 }
 
+// providerHTTPClient returns the HTTP client used for all API requests made
+// by the provider.
+func providerHTTPClient(d *schema.ResourceData) (*http.Client, diag.Diagnostics) {
+	// Durations are validated by the schema.
+	minWait, _ := time.ParseDuration(d.Get("retry_min_wait").(string))
+	maxWait, _ := time.ParseDuration(d.Get("retry_max_wait").(string))
+	if minWait > maxWait {
+		return nil, diag.Errorf("tailscale provider argument 'retry_min_wait' (%s) must not be greater than 'retry_max_wait' (%s)", minWait, maxWait)
+	}
+
+	transport, diags := providerTransport(d)
+	if diags.HasError() {
+		return nil, diags
+	}
+	var base http.RoundTripper = transport
+
+	// Trace each attempt of a request, so that retries are logged with their
+	// own status and latency.
+	trace := &traceTransport{
+		base:   base,
+		bodies: d.Get("log_http_bodies").(bool),
+	}
+	if path := os.Getenv(httpTraceFileEnvVar); path != "" {
+		trace.har = harRecorderFor(path)
+	}
+	base = trace
+
+	if rps := d.Get("requests_per_second").(float64); rps > 0 {
+		base = &rateLimitTransport{
+			base:    base,
+			limiter: rate.NewLimiter(rate.Limit(rps), d.Get("burst").(int)),
+		}
+	}
+
+	base = &retryTransport{
+		base:       base,
+		maxRetries: d.Get("max_retries").(int),
+		minWait:    minWait,
+		maxWait:    maxWait,
+	}
+
+	// Rejected requests are not retried.
+	if d.Get("read_only").(bool) {
+		base = &readOnlyTransport{base: base}
+	}
+
+	return &http.Client{Transport: base}, nil
+}
+
 func validateProviderCreds(apiKey string, oauthClientID string, oauthClientSecret string, idToken string) diag.Diagnostics {
 	if apiKey == "" && oauthClientID == "" && oauthClientSecret == "" && idToken == "" {
 		return diag.Errorf("tailscale provider credentials are empty - set `api_key` or 'oauth_client_id' and either 'oauth_client_secret' or 'identity_token'")
@@ -246,6 +580,14 @@ func diagnosticsError(err error, message string, args ...interface{}) diag.Diagn
 	return diags
 }
 
+// validateDuration validates that a string is a duration, such as `30s`.
+func validateDuration(i interface{}, key string) ([]string, []error) {
+	if dur, err := time.ParseDuration(i.(string)); err != nil || dur < 0 {
+		return nil, []error{fmt.Errorf("expected %q to be a non-negative duration such as 30s, got %q", key, i)}
+	}
+	return nil, nil
+}
+
 func diagnosticsAsError(diags diag.Diagnostics) error {
 	var combined string
 	for _, d := range diags {
@@ -344,6 +686,15 @@ func optional[T any](d *schema.ResourceData, key string) *T {
 	return tailscale.PointerTo(d.Get(key).(T))
 }
 
+// secretString returns the secret at key in the given resource, or the value
+// of its write-only variant at key_wo, which is only ever in the configuration.
+func secretString(d *schema.ResourceData, key string) string {
+	if v, _ := d.GetRawConfigAt(cty.GetAttrPath(key + "_wo")); v.IsKnown() && !v.IsNull() {
+		return v.AsString()
+	}
+	return d.Get(key).(string)
+}
+
 // isAcceptanceTesting returns true if we're running acceptance tests.
 func isAcceptanceTesting() bool {
 	return os.Getenv("TF_ACC") != ""
@@ -0,0 +1,118 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// frameworkProvider is the part of the provider built on
+// terraform-plugin-framework, which is served alongside the SDK provider by
+// ProviderServer so that resources and data sources can be moved to the
+// framework one at a time.
+//
+// Both providers share the same configuration: the schema of the framework
+// provider is derived from the SDK provider, and the framework provider uses
+// the client configured by the SDK provider.
+type frameworkProvider struct {
+	sdk *schema.Provider
+}
+
+var (
+	_ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+	_ provider.ProviderWithFunctions          = &frameworkProvider{}
+)
+
+func newFrameworkProvider(sdk *schema.Provider) provider.Provider {
+	return &frameworkProvider{sdk: sdk}
+}
+
+func (p *frameworkProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "tailscale"
+	resp.Version = providerVersion
+}
+
+func (p *frameworkProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	// The provider schemas of muxed servers must be identical.
+	attributes := make(map[string]providerschema.Attribute, len(p.sdk.Schema))
+	for name, s := range p.sdk.Schema {
+		attributes[name] = frameworkProviderAttribute(name, s)
+	}
+	resp.Schema = providerschema.Schema{Attributes: attributes}
+}
+
+// frameworkProviderAttribute returns the framework equivalent of an argument
+// of the SDK provider.
+func frameworkProviderAttribute(name string, s *schema.Schema) providerschema.Attribute {
+	var elemType schema.ValueType
+	if elem, ok := s.Elem.(*schema.Schema); ok {
+		elemType = elem.Type
+	}
+
+	switch {
+	case s.Type == schema.TypeString:
+		return providerschema.StringAttribute{Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+	case s.Type == schema.TypeBool:
+		return providerschema.BoolAttribute{Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+	case s.Type == schema.TypeInt:
+		return providerschema.Int64Attribute{Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+	case s.Type == schema.TypeFloat:
+		return providerschema.Float64Attribute{Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+	case s.Type == schema.TypeList && elemType == schema.TypeString:
+		return providerschema.ListAttribute{ElementType: types.StringType, Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+	case s.Type == schema.TypeSet && elemType == schema.TypeString:
+		return providerschema.SetAttribute{ElementType: types.StringType, Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+	default:
+		panic(fmt.Sprintf("provider argument %q has a type unsupported by the framework provider", name))
+	}
+}
+
+func (p *frameworkProvider) Configure(_ context.Context, _ provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	// The SDK provider is configured first by ProviderServer.
+	client, ok := p.sdk.Meta().(*tailscale.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Provider not configured", "The client of the tailscale provider has not been configured.")
+		return
+	}
+
+	resp.ResourceData = client
+	resp.DataSourceData = client
+	resp.EphemeralResourceData = client
+}
+
+func (p *frameworkProvider) Resources(context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) DataSources(context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newOAuthAccessTokenEphemeralResource,
+		newTailnetKeyEphemeralResource,
+	}
+}
+
+func (p *frameworkProvider) Functions(context.Context) []func() function.Function {
+	return []func() function.Function{
+		newCGNATRangeFunction,
+		newIsTailscaleIPFunction,
+		newUnmapVia6Function,
+		newVia6Function,
+	}
+}
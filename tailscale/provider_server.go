@@ -0,0 +1,116 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// policyAttributes maps resources managing the whole policy file to the
+// attribute holding its contents.
+var policyAttributes = map[string]string{
+	"tailscale_acl":    "acl",
+	"tailscale_policy": "policy",
+}
+
+// ProviderServer returns the gRPC server for the provider, which serves the
+// SDK provider and the framework provider together.
+func ProviderServer(ctx context.Context) (tfprotov5.ProviderServer, error) {
+	return newProviderServer(ctx, Provider())
+}
+
+// newProviderServer muxes the server of the given SDK provider with the
+// server of the framework provider derived from it.
+func newProviderServer(ctx context.Context, provider *schema.Provider) (tfprotov5.ProviderServer, error) {
+	// The SDK provider must come first, as the framework provider uses the
+	// client it configures.
+	server, err := tf5muxserver.NewMuxServer(ctx,
+		func() tfprotov5.ProviderServer { return newPolicyDiffServer(provider) },
+		providerserver.NewProtocol5(newFrameworkProvider(provider)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return server.ProviderServer(), nil
+}
+
+// policyDiffServer wraps the provider server to show the semantic changes to
+// the policy file as a warning when planning an update. CustomizeDiff can only
+// return errors, so the changes are computed from the planned state instead.
+type policyDiffServer struct {
+	tfprotov5.ProviderServer
+	provider *schema.Provider
+}
+
+func newPolicyDiffServer(provider *schema.Provider) tfprotov5.ProviderServer {
+	return &policyDiffServer{
+		ProviderServer: schema.NewGRPCProviderServer(provider),
+		provider:       provider,
+	}
+}
+
+func (s *policyDiffServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.PlanResourceChange(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	attribute, ok := policyAttributes[req.TypeName]
+	if !ok {
+		return resp, nil
+	}
+
+	ty := s.provider.ResourcesMap[req.TypeName].CoreConfigSchema().ImpliedType()
+	oldPolicy, ok := planStringAttribute(req.PriorState, ty, attribute)
+	if !ok {
+		return resp, nil
+	}
+	newPolicy, ok := planStringAttribute(resp.PlannedState, ty, attribute)
+	if !ok {
+		return resp, nil
+	}
+
+	changes, err := diffPolicies(oldPolicy, newPolicy)
+	if err != nil || len(changes) == 0 {
+		// Invalid policies are reported elsewhere.
+		return resp, nil
+	}
+
+	resp.Diagnostics = append(resp.Diagnostics, &tfprotov5.Diagnostic{
+		Severity:  tfprotov5.DiagnosticSeverityWarning,
+		Summary:   fmt.Sprintf("Policy file changes (%d)", len(changes)),
+		Detail:    "This plan makes the following changes to the policy file:\n\n" + strings.Join(changes, "\n"),
+		Attribute: tftypes.NewAttributePath().WithAttributeName(attribute),
+	})
+	return resp, nil
+}
+
+// planStringAttribute returns the value of the given string attribute of a
+// resource, if the resource exists and the value is known.
+func planStringAttribute(value *tfprotov5.DynamicValue, ty cty.Type, attribute string) (string, bool) {
+	if value == nil || len(value.MsgPack) == 0 {
+		return "", false
+	}
+
+	v, err := msgpack.Unmarshal(value.MsgPack, ty)
+	if err != nil || v.IsNull() || !v.IsKnown() {
+		return "", false
+	}
+
+	attr := v.GetAttr(attribute)
+	if attr.IsNull() || !attr.IsKnown() || attr.Type() != cty.String {
+		return "", false
+	}
+	return attr.AsString(), true
+}
@@ -0,0 +1,37 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+)
+
+func TestProviderServer(t *testing.T) {
+	ctx := context.Background()
+	server, err := ProviderServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mux server fails if the provider schemas of the SDK and framework
+	// providers differ.
+	resp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+	if got, want := len(resp.Provider.Block.Attributes), len(Provider().Schema); got != want {
+		t.Errorf("expected %d provider arguments, got %d", want, got)
+	}
+	if _, ok := resp.ResourceSchemas["tailscale_acl"]; !ok {
+		t.Error("expected the resources of the SDK provider to be served")
+	}
+}
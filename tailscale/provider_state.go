@@ -0,0 +1,93 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+	"weak"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// providerStates holds the providerState of each configured provider, keyed
+// by the client returned by providerConfigure, and of the clients for its
+// other tailnets. The keys are weak pointers, and entries are removed once
+// their client is garbage collected, so that providers configured again, as
+// in tests, do not leak their state.
+var providerStates sync.Map
+
+// providerState is the part of a configured provider that is not held by its
+// tailscale.Client. It is set once, when the provider is configured, and
+// copied as a whole to the clients for other tailnets.
+type providerState struct {
+	// httpClient is the HTTP client of the provider, before it is wrapped
+	// with the authentication of the provider.
+	httpClient *http.Client
+	// headscale is set if the provider uses the headscale backend.
+	headscale *headscaleClient
+	// tagPolicy is set if the provider sets allowed tag patterns or default
+	// tags.
+	tagPolicy *tagPolicy
+	readOnly  bool
+	// deviceCache is the device cache of the tailnet of the client, if the
+	// device cache is enabled.
+	deviceCache *deviceCache
+	// tailnets holds the clients for the other tailnets of the provider.
+	tailnets *tailnetPool
+}
+
+// setProviderState sets the state of the given client.
+func setProviderState(client *tailscale.Client, state *providerState) {
+	if state.tailnets == nil {
+		state.tailnets = &tailnetPool{}
+	}
+	key := weak.Make(client)
+	providerStates.Store(key, state)
+	removeProviderStateOnCleanup(client, key)
+}
+
+// providerStateFor returns the state of the given client. Clients that were
+// not configured by providerConfigure, such as those of test harnesses, have
+// an empty state.
+func providerStateFor(client *tailscale.Client) *providerState {
+	key := weak.Make(client)
+	if state, ok := providerStates.Load(key); ok {
+		return state.(*providerState)
+	}
+	state, loaded := providerStates.LoadOrStore(key, &providerState{tailnets: &tailnetPool{}})
+	if !loaded {
+		removeProviderStateOnCleanup(client, key)
+	}
+	return state.(*providerState)
+}
+
+func removeProviderStateOnCleanup(client *tailscale.Client, key weak.Pointer[tailscale.Client]) {
+	runtime.AddCleanup(client, func(key weak.Pointer[tailscale.Client]) {
+		providerStates.Delete(key)
+	}, key)
+}
+
+// forTailnet returns a copy of the state for the client of another tailnet,
+// with its own device cache.
+func (s *providerState) forTailnet() *providerState {
+	state := *s
+	state.tailnets = nil
+	if s.deviceCache != nil {
+		state.deviceCache = newDeviceCache(s.deviceCache.ttl)
+	}
+	return &state
+}
+
+// providerHTTPClientFor returns the HTTP client of the given provider client,
+// without its authentication.
+func providerHTTPClientFor(client *tailscale.Client) (*http.Client, error) {
+	httpClient := providerStateFor(client).httpClient
+	if httpClient == nil {
+		return nil, errors.New("the HTTP client of the provider is not configured")
+	}
+	return httpClient, nil
+}
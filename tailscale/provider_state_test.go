@@ -0,0 +1,82 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"net/http"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+func TestProviderState_Tailnets(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com")
+	client := &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}
+	httpClient := &http.Client{}
+	policy := &tagPolicy{defaultTags: []string{"tag:default"}}
+	hs := &headscaleClient{baseURL: baseURL}
+	setProviderState(client, &providerState{
+		httpClient:  httpClient,
+		headscale:   hs,
+		tagPolicy:   policy,
+		readOnly:    true,
+		deviceCache: newDeviceCache(time.Hour),
+	})
+
+	other := tailnetClient(client, "other.example.com")
+	if other == client || other.Tailnet != "other.example.com" {
+		t.Fatalf("expected a client for the other tailnet, got %+v", other)
+	}
+	if again := tailnetClient(client, "other.example.com"); again != other {
+		t.Error("expected the client of the other tailnet to be reused")
+	}
+
+	if got, err := providerHTTPClientFor(other); err != nil || got != httpClient {
+		t.Errorf("expected the HTTP client of the provider, got %v, %v", got, err)
+	}
+	if headscaleClientFor(other) != hs {
+		t.Error("expected the headscale client of the provider")
+	}
+	if tagPolicyFor(other) != policy {
+		t.Error("expected the tag policy of the provider")
+	}
+	if !isReadOnly(other) {
+		t.Error("expected the client of the other tailnet to be read-only")
+	}
+	cache := deviceCacheFor(other)
+	if cache == nil || cache == deviceCacheFor(client) || cache.ttl != time.Hour {
+		t.Errorf("expected a separate device cache with the TTL of the provider, got %+v", cache)
+	}
+}
+
+func TestProviderState_Unconfigured(t *testing.T) {
+	client := &tailscale.Client{Tailnet: "example.com"}
+	if _, err := providerHTTPClientFor(client); err == nil {
+		t.Error("expected an error for the HTTP client of an unconfigured client")
+	}
+	if isReadOnly(client) || headscaleClientFor(client) != nil || tagPolicyFor(client) != nil || deviceCacheFor(client) != nil {
+		t.Error("expected an empty state for an unconfigured client")
+	}
+}
+
+func TestProviderState_RemovedWithClient(t *testing.T) {
+	client := &tailscale.Client{Tailnet: "example.com"}
+	setProviderState(client, &providerState{readOnly: true})
+	key := weak.Make(client)
+	client = nil
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		runtime.GC()
+		if _, ok := providerStates.Load(key); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected the state to be removed once the client is garbage collected")
+}
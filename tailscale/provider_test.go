@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -51,12 +52,21 @@ func testAccPreCheck(t *testing.T) {
 	}
 }
 
-func testAccProviderFactories(t *testing.T) map[string]func() (*schema.Provider, error) {
+func testAccProviderFactories(t *testing.T) map[string]func() (tfprotov5.ProviderServer, error) {
 	t.Helper()
 
-	return map[string]func() (*schema.Provider, error){
-		"tailscale": func() (*schema.Provider, error) {
-			return Provider(), nil
+	return testMuxedProviderFactories(func() *schema.Provider {
+		return Provider()
+	})
+}
+
+// testMuxedProviderFactories returns the provider factories of the server
+// muxing the given SDK provider with the framework provider, as served by
+// ProviderServer.
+func testMuxedProviderFactories(provider func() *schema.Provider) map[string]func() (tfprotov5.ProviderServer, error) {
+	return map[string]func() (tfprotov5.ProviderServer, error){
+		"tailscale": func() (tfprotov5.ProviderServer, error) {
+			return newProviderServer(context.Background(), provider())
 		},
 	}
 }
@@ -71,24 +81,43 @@ func TestProvider_Implemented(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
 
-func testProviderFactories(t *testing.T) map[string]func() (*schema.Provider, error) {
+func testProviderFactories(t *testing.T) map[string]func() (tfprotov5.ProviderServer, error) {
 	t.Helper()
 
 	testClient, testServer = NewTestHarness(t)
-	return map[string]func() (*schema.Provider, error){
-		"tailscale": func() (*schema.Provider, error) {
-			return Provider(func(p *schema.Provider) {
-				// Set up a test harness for the provider
-				p.ConfigureContextFunc = func(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
-					return testClient, nil
-				}
+	return testMuxedProviderFactories(func() *schema.Provider {
+		return Provider(func(p *schema.Provider) {
+			// Set up a test harness for the provider
+			p.ConfigureContextFunc = func(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
+				return testClient, nil
+			}
 
-				// Don't require any of the global configuration
-				p.Schema = nil
-			}), nil
-		},
-	}
+			// Don't require any of the global configuration
+			p.Schema = nil
+		})
+	})
+}
+
+// testConfiguredProviderFactories returns the provider factories of the
+// provider itself, configured by the provider block of the test
+// configuration rather than a test harness.
+func testConfiguredProviderFactories() map[string]func() (tfprotov5.ProviderServer, error) {
+	return testMuxedProviderFactories(func() *schema.Provider {
+		return Provider()
+	})
+}
+
+// testRequiredProviders declares the provider as served by the test provider
+// factories, which configurations calling provider functions must do.
+const testRequiredProviders = `
+terraform {
+  required_providers {
+    tailscale = {
+      source = "hashicorp/tailscale"
+    }
+  }
 }
+`
 
 func testResourceCreated(name, hcl string) resource.TestStep {
 	return resource.TestStep{
@@ -0,0 +1,78 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// readOnlyPaths are the suffixes of the paths that a read-only provider may
+// still send POST requests to, as they do not change the tailnet: obtaining
+// access tokens, and validating policy files when planning.
+var readOnlyPaths = []string{
+	"/oauth/token",
+	"/oauth/token-exchange",
+	"/acl/validate",
+}
+
+// isReadOnly reports whether the given client is read-only.
+func isReadOnly(client *tailscale.Client) bool {
+	return providerStateFor(client).readOnly
+}
+
+// readOnlyTransport rejects all requests that may change the tailnet, in
+// case a read-only provider sends any despite its resources refusing to be
+// created, updated or deleted.
+type readOnlyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.base.RoundTrip(req)
+	case http.MethodPost:
+		for _, suffix := range readOnlyPaths {
+			if strings.HasSuffix(req.URL.Path, suffix) {
+				return t.base.RoundTrip(req)
+			}
+		}
+	}
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return nil, fmt.Errorf("tailscale provider is read-only, refusing to send %s %s", req.Method, req.URL.Path)
+}
+
+// withReadOnly makes the create, update and delete functions of a resource
+// fail without calling the API if the provider is read-only.
+func withReadOnly(r *schema.Resource, name string) {
+	guard := func(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics, action string) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if isReadOnly(m.(*tailscale.Client)) {
+				return diag.Diagnostics{{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("%s cannot be %s, as the provider is read-only", name, action),
+					Detail:   "The tailscale provider is configured with 'read_only', which prevents any change to the tailnet. Only run plans with this provider.",
+				}}
+			}
+			return fn(ctx, d, m)
+		}
+	}
+
+	r.CreateContext = guard(r.CreateContext, "created")
+	r.UpdateContext = guard(r.UpdateContext, "updated")
+	r.DeleteContext = guard(r.DeleteContext, "deleted")
+}
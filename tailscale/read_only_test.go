@@ -0,0 +1,116 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// newReadOnlyTestServer returns the URL of a stand-in for the API recording
+// the requests it receives.
+func newReadOnlyTestServer(t *testing.T) (string, func() []string) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		if strings.HasSuffix(r.URL.Path, "/acl/validate") {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		testAPIHandler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	baseURL, requests := newReadOnlyTestServer(t)
+	client, err := testConfigureProvider(t, map[string]string{
+		"api_key":   "tskey-api-test",
+		"tailnet":   "example.com",
+		"base_url":  baseURL,
+		"read_only": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.Devices().List(ctx); err != nil {
+		t.Errorf("expected devices to be listed, got %v", err)
+	}
+	if err := client.PolicyFile().Validate(ctx, `{}`); err != nil {
+		t.Errorf("expected the policy file to be validated, got %v", err)
+	}
+	if err := client.Devices().Delete(ctx, "12345"); err == nil || !strings.Contains(err.Error(), "read-only, refusing to send DELETE") {
+		t.Errorf("expected the device deletion to be rejected, got %v", err)
+	}
+	if _, err := membershipAPI(client).createUserInvite(ctx, "alice@example.com", "member"); err == nil || !strings.Contains(err.Error(), "read-only, refusing to send POST") {
+		t.Errorf("expected the user invite to be rejected, got %v", err)
+	}
+
+	want := []string{
+		"GET /api/v2/tailnet/example.com/devices",
+		"POST /api/v2/tailnet/example.com/acl/validate",
+	}
+	if err := assertEqual(want, requests(), "wrong requests sent"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProvider_ReadOnly(t *testing.T) {
+	baseURL, requests := newReadOnlyTestServer(t)
+	config := fmt.Sprintf(`
+		provider "tailscale" {
+			api_key   = "tskey-api-test"
+			tailnet   = "example.com"
+			base_url  = %q
+			read_only = true
+		}
+
+		resource "tailscale_tailnet_key" "test" {
+			reusable = true
+		}`, baseURL)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(`tailscale_tailnet_key cannot be created, as the provider is read-only`),
+			},
+		},
+	})
+
+	if got := requests(); len(got) > 0 {
+		t.Errorf("expected no requests, got %v", got)
+	}
+}
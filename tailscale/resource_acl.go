@@ -6,11 +6,11 @@ package tailscale
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pmezard/go-difflib/difflib"
 
 	"tailscale.com/client/tailscale/v2"
 
@@ -19,7 +19,9 @@ import (
 
 const resourceACLDescription = `The acl resource allows you to configure a Tailscale policy file. See https://tailscale.com/kb/1395/tailnet-policy-file for more information. Note that this resource will completely overwrite existing policy file contents for a given tailnet.
 
-If tests are defined in the policy file (the top-level "tests" section), policy file validation will occur before creation and update operations are applied.`
+If tests are defined in the policy file (the top-level "tests" section), policy file validation will occur before creation and update operations are applied.
+
+The policy file is also checked locally for common mistakes, such as unknown sections, references to undefined groups or tags, duplicate host aliases and malformed CIDRs or ports. These checks do not require access to the Tailscale API.`
 
 // From https://github.com/hashicorp/terraform-plugin-sdk/blob/34d8a9ebca6bed68fddb983123d6fda72481752c/internal/configs/hcl2shim/values.go#L19
 // TODO: use an exported variable when https://github.com/hashicorp/terraform-plugin-sdk/issues/803 has been addressed.
@@ -36,13 +38,19 @@ func resourceACL() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		CustomizeDiff: func(ctx context.Context, rd *schema.ResourceDiff, m interface{}) error {
-			client := m.(*tailscale.Client)
-
-			//if the acl is only known after apply, then acl will be an empty string and validation will fail
-			if rd.Get("acl").(string) == "" {
-				return nil
+			// The ETag changes whenever the policy file is updated.
+			oldACL, newACL := rd.GetChange("acl")
+			if rd.Id() != "" && (!rd.NewValueKnown("acl") || !equivalentHuJSON(oldACL.(string), newACL.(string))) {
+				if err := rd.SetNewComputed("etag"); err != nil {
+					return err
+				}
+				if rd.Get("keep_previous_acl").(bool) {
+					if err := rd.SetNewComputed("previous_acl"); err != nil {
+						return err
+					}
+				}
 			}
-			return client.PolicyFile().Validate(ctx, rd.Get("acl").(string))
+			return validatePolicy(ctx, m.(*tailscale.Client), rd.Get("acl").(string))
 		},
 		Schema: map[string]*schema.Schema{
 			"acl": {
@@ -50,28 +58,18 @@ func resourceACL() *schema.Resource {
 				Required:    true,
 				Description: "The policy that defines which devices and users are allowed to connect in your network. Can be either a JSON or a HuJSON string.",
 
-				// Field-level validation just checks that it's valid JSON or HuJSON.
-				// Actual contents of the policy is validated by calling the API when
-				// the whole resource is validated in CustomizeDiff.
-				ValidateDiagFunc: func(i interface{}, p cty.Path) diag.Diagnostics {
-					_, err := hujson.Parse([]byte(i.(string)))
-					if err != nil {
-						return diagnosticsErrorWithPath(err, "ACL is not a valid HuJSON string", p)
-					}
-					return nil
-				},
+				// Field-level validation checks that it's valid JSON or HuJSON and
+				// lints it locally, which works without access to the API. Actual
+				// contents of the policy is validated by calling the API when the
+				// whole resource is validated in CustomizeDiff.
+				ValidateDiagFunc: validatePolicyHuJSON,
 
 				// Do not show a diff if canonical HuJSON representation of the policy did not
 				// change. Note that a policy that is valid JSON will not be formatted as HuJSON
 				// (see hujson.Format docs), so a diff is expected when switching from JSON to
 				// HuJSON (or back), even if there are no semantic changes.
 				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
-					old, oldErr := hujson.Format([]byte(oldValue))
-					new, newErr := hujson.Format([]byte(newValue))
-					if oldErr != nil || newErr != nil {
-						return false
-					}
-					return string(old) == string(new)
+					return equivalentHuJSON(oldValue, newValue)
 				},
 				DiffSuppressOnRefresh: true,
 
@@ -98,10 +96,70 @@ func resourceACL() *schema.Resource {
 				Optional:    true,
 				Description: "If true, will reset the policy file for the Tailnet to the default when this resource is destroyed",
 			},
+			"overwrite_concurrent_changes": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If true, updates will overwrite changes made to the policy file outside of Terraform since it was last read. By default, such updates fail and show the changes that would have been lost",
+			},
+			"etag": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ETag of the policy file when it was last read, used to detect changes made outside of Terraform",
+			},
+			"keep_previous_acl": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If true, the policy file is saved to `previous_acl` before it is overwritten, so that it can be restored with a `tailscale_acl_rollback` resource",
+			},
+			"previous_acl": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The policy file as it was before it was last overwritten by this resource. Only set if `keep_previous_acl` is true",
+			},
 		},
 	}
 }
 
+// validatePolicyHuJSON checks that the policy is valid HuJSON, and reports each
+// problem found by lintPolicy as a separate diagnostic.
+func validatePolicyHuJSON(i interface{}, p cty.Path) diag.Diagnostics {
+	issues, err := lintPolicy(i.(string))
+	if err != nil {
+		return diagnosticsErrorWithPath(err, "ACL is not a valid HuJSON string", p)
+	}
+
+	var diags diag.Diagnostics
+	for _, issue := range issues {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Invalid policy file: " + issue.Message,
+			Detail:        fmt.Sprintf("Found at %s in the policy file.", issue.Path),
+			AttributePath: p,
+		})
+	}
+	return diags
+}
+
+// equivalentHuJSON reports whether the canonical HuJSON representations of a
+// and b are the same.
+func equivalentHuJSON(a, b string) bool {
+	formattedA, errA := hujson.Format([]byte(a))
+	formattedB, errB := hujson.Format([]byte(b))
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(formattedA) == string(formattedB)
+}
+
+// validatePolicy validates the given policy file contents using the API.
+func validatePolicy(ctx context.Context, client *tailscale.Client, policy string) error {
+	//if the acl is only known after apply, then acl will be an empty string and validation will fail
+	if policy == "" {
+		return nil
+	}
+	return client.PolicyFile().Validate(ctx, policy)
+}
+
 func resourceACLRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*tailscale.Client)
 	acl, err := client.PolicyFile().Raw(ctx)
@@ -109,36 +167,55 @@ func resourceACLRead(ctx context.Context, d *schema.ResourceData, m interface{})
 		return diagnosticsError(err, "Failed to fetch policy file")
 	}
 
-	if err := d.Set("acl", acl.HuJSON); err != nil {
-		return diag.FromErr(err)
-	}
-	return nil
+	return setProperties(d, map[string]any{
+		"acl":  acl.HuJSON,
+		"etag": acl.ETag,
+	})
 }
 
 func resourceACLCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*tailscale.Client)
 	acl := d.Get("acl").(string)
 
+	previous, diags := snapshotPolicy(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	if diags := setInitialPolicy(ctx, client, "tailscale_acl", acl, d.Get("overwrite_existing_content").(bool)); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(createUUID())
+	if err := d.Set("previous_acl", previous); err != nil {
+		return diagnosticsError(err, "Failed to set previous_acl")
+	}
+	return resourceACLRead(ctx, d, m)
+}
+
+// setInitialPolicy sets the policy file when a resource managing it is created.
+// Unless overwrite is true, this only succeeds if the policy file has never
+// been changed from its default value.
+func setInitialPolicy(ctx context.Context, client *tailscale.Client, resourceType, policy string, overwrite bool) diag.Diagnostics {
 	// Setting the `ts-default` ETag will make this operation succeed only if
 	// ACL contents has never been changed from its default value.
 	var etag string
-	if !d.Get("overwrite_existing_content").(bool) {
+	if !overwrite {
 		etag = "ts-default"
 	}
 
-	if err := client.PolicyFile().Set(ctx, acl, etag); err != nil {
-		if strings.HasSuffix(err.Error(), "(412)") {
+	if err := client.PolicyFile().Set(ctx, policy, etag); err != nil {
+		if isPreconditionFailed(err) {
 			err = fmt.Errorf(
-				"! You seem to be trying to overwrite a non-default policy file with a tailscale_acl resource.\n"+
+				"! You seem to be trying to overwrite a non-default policy file with a %s resource.\n"+
 					"Before doing this, please import your existing policy file into Terraform state using:\n"+
 					" terraform import $(this_resource) acl\n"+
-					"(got error %q)", err)
+					"(got error %q)", resourceType, err)
 		}
 		return diagnosticsError(err, "Failed to set policy file")
 	}
 
-	d.SetId(createUUID())
-	return resourceACLRead(ctx, d, m)
+	return nil
 }
 
 func resourceACLUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -148,13 +225,84 @@ func resourceACLUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 		return nil
 	}
 
-	if err := client.PolicyFile().Set(ctx, d.Get("acl").(string), ""); err != nil {
+	// Only update the policy file if it has not changed since it was last read,
+	// unless explicitly configured otherwise.
+	var etag string
+	if !d.Get("overwrite_concurrent_changes").(bool) {
+		oldETag, _ := d.GetChange("etag")
+		etag = oldETag.(string)
+	}
+
+	previous, diags := snapshotPolicy(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := client.PolicyFile().Set(ctx, d.Get("acl").(string), etag); err != nil {
+		if isPreconditionFailed(err) {
+			expected, _ := d.GetChange("acl")
+			return policyConflictDiagnostics(ctx, client, expected.(string), err)
+		}
 		return diagnosticsError(err, "Failed to set policy file")
 	}
 
+	if err := d.Set("previous_acl", previous); err != nil {
+		return diagnosticsError(err, "Failed to set previous_acl")
+	}
 	return resourceACLRead(ctx, d, m)
 }
 
+// snapshotPolicy returns the current policy file if keep_previous_acl is set,
+// or the existing value of previous_acl otherwise.
+func snapshotPolicy(ctx context.Context, d *schema.ResourceData, client *tailscale.Client) (string, diag.Diagnostics) {
+	if !d.Get("keep_previous_acl").(bool) {
+		previous, _ := d.GetChange("previous_acl")
+		return previous.(string), nil
+	}
+
+	acl, err := client.PolicyFile().Raw(ctx)
+	if err != nil {
+		return "", diagnosticsError(err, "Failed to fetch policy file")
+	}
+	return acl.HuJSON, nil
+}
+
+// policyConflictDiagnostics returns diagnostics describing the changes made
+// to the policy file outside of Terraform, as a unified diff between the
+// policy file Terraform expected and the live policy file.
+func policyConflictDiagnostics(ctx context.Context, client *tailscale.Client, expected string, err error) diag.Diagnostics {
+	live, rawErr := client.PolicyFile().Raw(ctx)
+	if rawErr != nil {
+		return diagnosticsError(err, "Policy file was changed outside of Terraform")
+	}
+
+	diff, diffErr := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(formatPolicyForDiff(expected)),
+		B:        difflib.SplitLines(formatPolicyForDiff(live.HuJSON)),
+		FromFile: "expected",
+		ToFile:   "live",
+		Context:  3,
+	})
+	if diffErr != nil {
+		return diagnosticsError(err, "Policy file was changed outside of Terraform")
+	}
+
+	return diagnosticsError(fmt.Errorf(
+		"The policy file was changed since Terraform last read it, and applying this change would overwrite those changes.\n"+
+			"Run terraform apply again to plan against the live policy file, or set overwrite_concurrent_changes to overwrite it anyway.\n\n"+
+			"%s\n(got error %q)", diff, err), "Policy file was changed outside of Terraform")
+}
+
+// formatPolicyForDiff returns the canonical HuJSON representation of the
+// policy, or the policy itself if it cannot be parsed.
+func formatPolicyForDiff(policy string) string {
+	formatted, err := hujson.Format([]byte(policy))
+	if err != nil {
+		return policy
+	}
+	return string(formatted)
+}
+
 func resourceACLDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Each tailnet always has an associated ACL file, so deleting a resource will
 	// only remove it from Terraform state, leaving ACL contents intact.
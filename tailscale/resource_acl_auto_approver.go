@@ -0,0 +1,140 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLAutoApproverDescription = `The acl_auto_approver resource allows you to manage the auto approvers of a single route, or of exit nodes, in the Tailscale policy file. See https://tailscale.com/kb/1337/acl-syntax#autoapprovers for more information.
+
+Only the auto approvers of the route are managed by this resource; the rest of the policy file, including comments, is left intact. This resource must not be used together with the ` + "`tailscale_acl` or `tailscale_policy`" + ` resources, which manage the whole policy file.`
+
+// exitNodeAutoApproverID is the ID of the tailscale_acl_auto_approver
+// resource managing the auto approvers of exit nodes.
+const exitNodeAutoApproverID = "exitNode"
+
+func resourceACLAutoApprover() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLAutoApproverDescription,
+		ReadContext:   resourceACLAutoApproverRead,
+		CreateContext: resourceACLAutoApproverCreate,
+		UpdateContext: resourceACLAutoApproverUpdate,
+		DeleteContext: resourceACLAutoApproverDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"route": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "The route (CIDR) to auto approve. If not set, the auto approvers of exit nodes are managed instead.",
+				ValidateFunc: validation.IsCIDR,
+			},
+			"approvers": policyStringList("The users, groups and tags that can have the route or exit node auto approved.", true),
+		},
+	}
+}
+
+// autoApproverPath returns the sections and keys of the policy file managed
+// by the resource with the given ID.
+func autoApproverPath(id string) (sections []string, keys []string) {
+	if id == exitNodeAutoApproverID {
+		return []string{"autoApprovers", exitNodeAutoApproverID}, nil
+	}
+	return []string{"autoApprovers", "routes"}, []string{id}
+}
+
+func resourceACLAutoApproverRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	sections, keys := autoApproverPath(d.Id())
+	var approvers []string
+	err = getPolicyValue(policy, &approvers, sections, keys...)
+	if errors.Is(err, errPolicyFragmentNotFound) {
+		d.SetId("")
+		return nil
+	} else if err != nil {
+		return diagnosticsError(err, "Failed to read auto approvers")
+	}
+
+	var route string
+	if d.Id() != exitNodeAutoApproverID {
+		route = d.Id()
+	}
+
+	return setProperties(d, map[string]any{
+		"route":     route,
+		"approvers": approvers,
+	})
+}
+
+func resourceACLAutoApproverCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	id := d.Get("route").(string)
+	if id == "" {
+		id = exitNodeAutoApproverID
+	}
+
+	if diags := resourceACLAutoApproverSet(ctx, d, m, id, true); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(id)
+	return resourceACLAutoApproverRead(ctx, d, m)
+}
+
+func resourceACLAutoApproverUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := resourceACLAutoApproverSet(ctx, d, m, d.Id(), false); diags.HasError() {
+		return diags
+	}
+
+	return resourceACLAutoApproverRead(ctx, d, m)
+}
+
+func resourceACLAutoApproverSet(ctx context.Context, d *schema.ResourceData, m interface{}, id string, create bool) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	approvers := expandStrings(d.Get("approvers"))
+	sections, keys := autoApproverPath(id)
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		if create {
+			return createPolicyValue(policy, approvers, "tailscale_acl_auto_approver", policyImportID(d, id), sections, keys...)
+		}
+		return setPolicyValue(policy, approvers, sections, keys...)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to set auto approvers")
+	}
+
+	return nil
+}
+
+func resourceACLAutoApproverDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	sections, keys := autoApproverPath(d.Id())
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return removePolicyValue(policy, sections, keys...)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete auto approvers")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testACLAutoApprover = `
+	resource "tailscale_acl_auto_approver" "test_route" {
+		route     = "10.0.0.0/24"
+		approvers = ["tag:router"]
+	}
+
+	resource "tailscale_acl_auto_approver" "test_exit_node" {
+		approvers = ["tag:exit-node"]
+
+		depends_on = [tailscale_acl_auto_approver.test_route]
+	}`
+
+func TestProvider_TailscaleACLAutoApprover(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{"autoApprovers": {"routes": {"10.0.0.0/24": ["tag:router"]}, "exitNode": ["tag:exit-node"]}}`)
+			// The policy file only contains the entries once they have been
+			// created, one after the other.
+			routeOnly := []byte(`{"autoApprovers": {"routes": {"10.0.0.0/24": ["tag:router"]}}}`)
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/example.com/acl": {[]byte(`{}`), routeOnly, routeOnly},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_acl_auto_approver.test_route", Config: testACLAutoApprover},
+			testResourceDestroyed("tailscale_acl_auto_approver.test_route", testACLAutoApprover),
+		},
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLGrantDescription = `The acl_grant resource allows you to manage a single grant in the Tailscale policy file. See https://tailscale.com/kb/1324/grants for more information.
+
+Only the grant is managed by this resource; the rest of the policy file, including comments, is left intact. Grants are identified by their contents, so any change to a grant replaces it. This resource must not be used together with the ` + "`tailscale_acl` or `tailscale_policy`" + ` resources, which manage the whole policy file.`
+
+func resourceACLGrant() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLGrantDescription,
+		ReadContext:   resourceACLGrantRead,
+		CreateContext: resourceACLGrantCreate,
+		DeleteContext: resourceACLGrantDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceACLGrantImport,
+		},
+		Schema: policyGrantSchema(true),
+	}
+}
+
+func resourceACLGrantRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	grant, err := expandPolicyGrant(resourceDataMap(d, policyGrantSchema(true)))
+	if err != nil {
+		return diagnosticsError(err, "Failed to read grant")
+	}
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	_, index, err := findPolicyRule(policy, "grants", grant)
+	if err != nil {
+		return diagnosticsError(err, "Failed to read grant")
+	}
+	if index < 0 {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourceACLGrantCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	grant, err := expandPolicyGrant(resourceDataMap(d, policyGrantSchema(true)))
+	if err != nil {
+		return diagnosticsError(err, "Failed to create grant")
+	}
+
+	err = updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return createPolicyRule(d, policy, "grants", grant, "tailscale_acl_grant")
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to create grant")
+	}
+
+	id, err := policyRuleID(grant)
+	if err != nil {
+		return diagnosticsError(err, "Failed to create grant")
+	}
+
+	d.SetId(id)
+	return resourceACLGrantRead(ctx, d, m)
+}
+
+func resourceACLGrantImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	client := m.(*tailscale.Client)
+
+	var grant tailscale.Grant
+	if err := importPolicyRule(ctx, d, client, "grants", &grant); err != nil {
+		return nil, err
+	}
+
+	props, err := flattenPolicyGrant(grant)
+	if err != nil {
+		return nil, err
+	}
+	if diags := setProperties(d, props); diags.HasError() {
+		return nil, diagnosticsAsError(diags)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceACLGrantDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	grant, err := expandPolicyGrant(resourceDataMap(d, policyGrantSchema(true)))
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete grant")
+	}
+
+	err = updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return removePolicyRule(policy, "grants", grant)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete grant")
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+const testACLGrant = `
+	resource "tailscale_acl_grant" "test_grant" {
+		src = ["group:example"]
+		dst = ["tag:example"]
+		ip  = ["tcp:443"]
+	}`
+
+func TestProvider_TailscaleACLGrant(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{"grants": [{"src": ["group:example"], "dst": ["tag:example"], "ip": ["tcp:443"]}]}`)
+			// The policy file only contains the rule once it has been created.
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/example.com/acl": {[]byte(`{}`)},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_acl_grant.test_grant", Config: testACLGrant},
+			testResourceDestroyed("tailscale_acl_grant.test_grant", testACLGrant),
+		},
+	})
+}
+
+func TestResourceACLGrant(t *testing.T) {
+	t.Parallel()
+
+	client, ps := newTestPolicyServer(t, `{
+	"grants": [
+		// Everyone can reach the web servers.
+		{"src": ["*"], "dst": ["tag:web"], "ip": ["tcp:443"]},
+		{"src": ["group:ops"], "dst": ["tag:db"], "ip": ["*"]},
+	],
+}
+`)
+
+	d := resourceACLGrant().Data(nil)
+	d.SetId("1")
+	imported, err := resourceACLGrantImport(context.Background(), d, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grant, err := expandPolicyGrant(resourceDataMap(imported[0], policyGrantSchema(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tailscale.Grant{Source: []string{"group:ops"}, Destination: []string{"tag:db"}, IP: []string{"*"}}
+	if err := assertEqual(want, grant, "wrong imported grant"); err != nil {
+		t.Error(err)
+	}
+	if id, _ := policyRuleID(want); imported[0].Id() != id {
+		t.Errorf("expected the ID of an imported grant to match the ID of a created one, got %q", imported[0].Id())
+	}
+
+	missing := resourceACLGrant().Data(nil)
+	missing.SetId("2")
+	if _, err := resourceACLGrantImport(context.Background(), missing, client); err == nil {
+		t.Error("expected an error importing a missing grant")
+	}
+
+	if diags := resourceACLGrantDelete(context.Background(), imported[0], client); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	wantPolicy := `{
+	"grants": [
+		// Everyone can reach the web servers.
+		{"src": ["*"], "dst": ["tag:web"], "ip": ["tcp:443"]},
+	],
+}
+`
+	if err := assertEqual(wantPolicy, ps.policy, "wrong policy after delete"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResourceACLGrantCreateExisting(t *testing.T) {
+	t.Parallel()
+
+	const policy = `{
+	"grants": [
+		{"src": ["*"], "dst": ["tag:web"], "ip": ["tcp:443"]},
+		{"src": ["group:ops"], "dst": ["tag:db"], "ip": ["*"]},
+	],
+}
+`
+	client, ps := newTestPolicyServer(t, policy)
+
+	d := resourceACLGrant().Data(nil)
+	d.Set("src", []string{"group:ops"})
+	d.Set("dst", []string{"tag:db"})
+	d.Set("ip", []string{"*"})
+	diags := resourceACLGrantCreate(context.Background(), d, client)
+	if !diags.HasError() {
+		t.Fatal("expected an error creating a grant equivalent to an existing one")
+	}
+	if detail := diags[0].Detail; !strings.Contains(detail, `"/grants/1"`) || !strings.HasSuffix(detail, "terraform import $(this_resource) 1") {
+		t.Errorf("expected an error asking to import grant 1, got %q", detail)
+	}
+	if err := assertEqual(policy, ps.policy, "wrong policy after a failed create"); err != nil {
+		t.Error(err)
+	}
+}
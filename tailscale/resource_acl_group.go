@@ -0,0 +1,115 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLGroupDescription = `The acl_group resource allows you to manage a single group in the Tailscale policy file. See https://tailscale.com/kb/1337/acl-syntax#groups for more information.
+
+Only the group is managed by this resource; the rest of the policy file, including comments, is left intact. This resource must not be used together with the ` + "`tailscale_acl` or `tailscale_policy`" + ` resources, which manage the whole policy file.`
+
+func resourceACLGroup() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLGroupDescription,
+		ReadContext:   resourceACLGroupRead,
+		CreateContext: resourceACLGroupCreate,
+		UpdateContext: resourceACLGroupUpdate,
+		DeleteContext: resourceACLGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The name of the group, starting with `group:`.",
+				ValidateFunc: validation.StringMatch(groupNameRegexp, "group names must start with `group:`"),
+			},
+			"members": policyStringList("The members of the group.", true),
+		},
+	}
+}
+
+func resourceACLGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	var members []string
+	err = getPolicyValue(policy, &members, []string{"groups"}, d.Id())
+	if errors.Is(err, errPolicyFragmentNotFound) {
+		d.SetId("")
+		return nil
+	} else if err != nil {
+		return diagnosticsError(err, "Failed to read group")
+	}
+
+	return setProperties(d, map[string]any{
+		"name":    d.Id(),
+		"members": members,
+	})
+}
+
+func resourceACLGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	name := d.Get("name").(string)
+	if diags := resourceACLGroupSet(ctx, d, m, name, true); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(name)
+	return resourceACLGroupRead(ctx, d, m)
+}
+
+func resourceACLGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := resourceACLGroupSet(ctx, d, m, d.Id(), false); diags.HasError() {
+		return diags
+	}
+
+	return resourceACLGroupRead(ctx, d, m)
+}
+
+func resourceACLGroupSet(ctx context.Context, d *schema.ResourceData, m interface{}, name string, create bool) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	members := expandStrings(d.Get("members"))
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		if create {
+			return createPolicyValue(policy, members, "tailscale_acl_group", policyImportID(d, name), []string{"groups"}, name)
+		}
+		return setPolicyValue(policy, members, []string{"groups"}, name)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to set group")
+	}
+
+	return nil
+}
+
+func resourceACLGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return removePolicyValue(policy, []string{"groups"}, d.Id())
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete group")
+	}
+
+	return nil
+}
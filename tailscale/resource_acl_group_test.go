@@ -0,0 +1,169 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+const testACLGroup = `
+	resource "tailscale_acl_group" "test_group" {
+		name    = "group:example"
+		members = ["user1@example.com", "user2@example.com"]
+	}`
+
+func TestProvider_TailscaleACLGroup(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{
+				// Comments are ignored.
+				"groups": {"group:example": ["user1@example.com", "user2@example.com"]},
+			}`)
+			// The policy file only contains the entry once it has been created.
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/example.com/acl": {[]byte(`{}`)},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_acl_group.test_group", Config: testACLGroup},
+			testResourceDestroyed("tailscale_acl_group.test_group", testACLGroup),
+		},
+	})
+}
+
+func TestAccTailscaleACLGroup(t *testing.T) {
+	const resourceName = "tailscale_acl_group.test_group"
+
+	const testACLGroupUpdate = `
+		resource "tailscale_acl_group" "test_group" {
+			name    = "group:example"
+			members = ["user3@example.com"]
+		}`
+
+	checkProperties := func(expected []string) func(client *tailscale.Client, rs *terraform.ResourceState) error {
+		return func(client *tailscale.Client, rs *terraform.ResourceState) error {
+			acl, err := client.PolicyFile().Get(context.Background())
+			if err != nil {
+				return err
+			}
+
+			return assertEqual(expected, acl.Groups["group:example"], "wrong group members")
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProviderFactories(t),
+		CheckDestroy:             checkResourceDestroyed(resourceName, checkProperties(nil)),
+		Steps: []resource.TestStep{
+			{
+				Config: testACLGroup,
+				Check: resource.ComposeTestCheckFunc(
+					checkResourceRemoteProperties(resourceName,
+						checkProperties([]string{"user1@example.com", "user2@example.com"}),
+					),
+					resource.TestCheckResourceAttr(resourceName, "members.#", "2"),
+				),
+			},
+			{
+				Config: testACLGroupUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					checkResourceRemoteProperties(resourceName,
+						checkProperties([]string{"user3@example.com"}),
+					),
+					resource.TestCheckResourceAttr(resourceName, "members.#", "1"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceACLGroup(t *testing.T) {
+	t.Parallel()
+
+	client, ps := newTestPolicyServer(t, `{
+	// Managed by several teams.
+	"hosts": {"router": "100.64.0.1"},
+	"groups": {
+		"group:other": ["other@example.com"], // Not managed by Terraform.
+	},
+}
+`)
+	// Another group is added between reading and writing the policy file.
+	ps.concurrentChanges = []string{`{
+	// Managed by several teams.
+	"hosts": {"router": "100.64.0.1"},
+	"groups": {
+		"group:other":      ["other@example.com"], // Not managed by Terraform.
+		"group:concurrent": ["concurrent@example.com"],
+	},
+}
+`}
+
+	d := schema.TestResourceDataRaw(t, resourceACLGroup().Schema, map[string]interface{}{
+		"name":    "group:example",
+		"members": []interface{}{"user1@example.com"},
+	})
+	if diags := resourceACLGroupCreate(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	want := `{
+	// Managed by several teams.
+	"hosts": {"router": "100.64.0.1"},
+	"groups": {
+		"group:other":      ["other@example.com"], // Not managed by Terraform.
+		"group:concurrent": ["concurrent@example.com"],
+		"group:example":    ["user1@example.com"],
+	},
+}
+`
+	if err := assertEqual(want, ps.policy, "wrong policy after create"); err != nil {
+		t.Error(err)
+	}
+
+	// Groups that already exist must be imported instead.
+	existing := schema.TestResourceDataRaw(t, resourceACLGroup().Schema, map[string]interface{}{
+		"name":    "group:other",
+		"members": []interface{}{"user1@example.com"},
+	})
+	diags := resourceACLGroupCreate(context.Background(), existing, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "terraform import $(this_resource) group:other") {
+		t.Errorf("expected an error pointing to terraform import, got %v", diags)
+	}
+
+	if diags := resourceACLGroupDelete(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	want = `{
+	// Managed by several teams.
+	"hosts": {"router": "100.64.0.1"},
+	"groups": {
+		"group:other":      ["other@example.com"], // Not managed by Terraform.
+		"group:concurrent": ["concurrent@example.com"],
+	},
+}
+`
+	if err := assertEqual(want, ps.policy, "wrong policy after delete"); err != nil {
+		t.Error(err)
+	}
+}
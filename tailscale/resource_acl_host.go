@@ -0,0 +1,120 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLHostDescription = `The acl_host resource allows you to manage a single host alias in the Tailscale policy file. See https://tailscale.com/kb/1337/acl-syntax#hosts for more information.
+
+Only the host alias is managed by this resource; the rest of the policy file, including comments, is left intact. This resource must not be used together with the ` + "`tailscale_acl` or `tailscale_policy`" + ` resources, which manage the whole policy file.`
+
+func resourceACLHost() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLHostDescription,
+		ReadContext:   resourceACLHostRead,
+		CreateContext: resourceACLHostCreate,
+		UpdateContext: resourceACLHostUpdate,
+		DeleteContext: resourceACLHostDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The name of the host alias.",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"address": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The IP address or CIDR range the host alias refers to.",
+				ValidateFunc: validation.Any(validation.IsIPAddress, validation.IsCIDR),
+			},
+		},
+	}
+}
+
+func resourceACLHostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	var address string
+	err = getPolicyValue(policy, &address, []string{"hosts"}, d.Id())
+	if errors.Is(err, errPolicyFragmentNotFound) {
+		d.SetId("")
+		return nil
+	} else if err != nil {
+		return diagnosticsError(err, "Failed to read host")
+	}
+
+	return setProperties(d, map[string]any{
+		"name":    d.Id(),
+		"address": address,
+	})
+}
+
+func resourceACLHostCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	name := d.Get("name").(string)
+	if diags := resourceACLHostSet(ctx, d, m, name, true); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(name)
+	return resourceACLHostRead(ctx, d, m)
+}
+
+func resourceACLHostUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := resourceACLHostSet(ctx, d, m, d.Id(), false); diags.HasError() {
+		return diags
+	}
+
+	return resourceACLHostRead(ctx, d, m)
+}
+
+func resourceACLHostSet(ctx context.Context, d *schema.ResourceData, m interface{}, name string, create bool) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	address := d.Get("address").(string)
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		if create {
+			return createPolicyValue(policy, address, "tailscale_acl_host", policyImportID(d, name), []string{"hosts"}, name)
+		}
+		return setPolicyValue(policy, address, []string{"hosts"}, name)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to set host")
+	}
+
+	return nil
+}
+
+func resourceACLHostDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return removePolicyValue(policy, []string{"hosts"}, d.Id())
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete host")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testACLHost = `
+	resource "tailscale_acl_host" "test_host" {
+		name    = "example-host"
+		address = "100.100.100.100"
+	}`
+
+func TestProvider_TailscaleACLHost(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{"hosts": {"example-host": "100.100.100.100"}}`)
+			// The policy file only contains the entry once it has been created.
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/example.com/acl": {[]byte(`{}`)},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_acl_host.test_host", Config: testACLHost},
+			testResourceDestroyed("tailscale_acl_host.test_host", testACLHost),
+		},
+	})
+}
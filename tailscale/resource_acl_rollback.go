@@ -0,0 +1,81 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLRollbackDescription = `The acl_rollback resource re-applies a snapshot of the Tailscale policy file, such as the ` + "`previous_acl`" + ` of a ` + "`tailscale_acl`" + ` resource with ` + "`keep_previous_acl`" + ` enabled.
+
+The snapshot is applied when this resource is created, and again whenever ` + "`acl`" + ` changes. If ` + "`etag`" + ` is set, the snapshot is only applied if the policy file has not changed since, so that a rollback does not overwrite unrelated changes. Destroying this resource does not change the policy file.
+
+This resource is meant to be added to the configuration for a single targeted apply, and removed once the rollback is done. After rolling back, update the configuration of the ` + "`tailscale_acl`" + ` resource to match the restored policy file, otherwise the next apply will overwrite it again.`
+
+func resourceACLRollback() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLRollbackDescription,
+		ReadContext:   schema.NoopContext,
+		CreateContext: resourceACLRollbackApply,
+		UpdateContext: resourceACLRollbackUpdate,
+		DeleteContext: schema.NoopContext,
+		Schema: map[string]*schema.Schema{
+			"acl": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The policy file snapshot to apply, as a JSON or HuJSON string.",
+				// Only check that the snapshot is valid HuJSON, so that a rollback
+				// is never blocked by local linting.
+				ValidateDiagFunc: func(i interface{}, p cty.Path) diag.Diagnostics {
+					if _, err := hujson.Parse([]byte(i.(string))); err != nil {
+						return diagnosticsErrorWithPath(err, "ACL is not a valid HuJSON string", p)
+					}
+					return nil
+				},
+			},
+			"etag": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ETag the policy file is expected to have, such as the `etag` of a `tailscale_acl` resource. If set, the snapshot is only applied if the policy file has not changed since.",
+			},
+		},
+	}
+}
+
+func resourceACLRollbackUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// A changed ETag alone is expected after the policy file is updated, and
+	// must not re-apply the snapshot.
+	if !d.HasChange("acl") {
+		return nil
+	}
+	return resourceACLRollbackApply(ctx, d, m)
+}
+
+func resourceACLRollbackApply(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	etag := d.Get("etag").(string)
+
+	if err := client.PolicyFile().Set(ctx, d.Get("acl").(string), etag); err != nil {
+		if isPreconditionFailed(err) {
+			err = fmt.Errorf(
+				"the policy file was changed since it had ETag %q, so it was not rolled back.\n"+
+					"Refresh the ETag to roll back anyway (got error %q)", etag, err)
+		}
+		return diagnosticsError(err, "Failed to roll back policy file")
+	}
+
+	if d.IsNewResource() {
+		d.SetId(createUUID())
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const testACLRollback = `
+	resource "tailscale_acl_rollback" "test_rollback" {
+		acl  = <<EOF
+		{
+			// The previous policy file.
+			"grants": [{"src": ["*"], "dst": ["*"], "ip": ["*"]}],
+		}
+		EOF
+		etag = "example-etag"
+	}`
+
+func TestProvider_TailscaleACLRollback(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = nil
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				ResourceName: "tailscale_acl_rollback.test_rollback",
+				Config:       testACLRollback,
+				Check: func(s *terraform.State) error {
+					if testServer.Method != http.MethodPost || !strings.HasSuffix(testServer.Path, "/acl") {
+						return fmt.Errorf("expected policy file to be set, got %s %s", testServer.Method, testServer.Path)
+					}
+					if !strings.Contains(testServer.Body.String(), "// The previous policy file.") {
+						return fmt.Errorf("expected snapshot to be applied, got %q", testServer.Body.String())
+					}
+					return nil
+				},
+			},
+			testResourceDestroyed("tailscale_acl_rollback.test_rollback", testACLRollback),
+		},
+	})
+}
+
+func TestProvider_TailscaleACLKeepPreviousACL(t *testing.T) {
+	const livePolicy = `{"hosts": {"example": "100.101.102.103"}}`
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(livePolicy)
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				ResourceName: "tailscale_acl.test_acl",
+				Config: `
+					resource "tailscale_acl" "test_acl" {
+						acl                        = jsonencode({ hosts = { example = "100.100.100.100" } })
+						overwrite_existing_content = true
+						keep_previous_acl          = true
+					}`,
+				ExpectNonEmptyPlan: true,
+				Check:              resource.TestCheckResourceAttr("tailscale_acl.test_acl", "previous_acl", livePolicy),
+			},
+		},
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLSSHDescription = `The acl_ssh resource allows you to manage a single SSH access rule in the Tailscale policy file. See https://tailscale.com/kb/1337/acl-syntax#tailscale-ssh for more information.
+
+Only the SSH access rule is managed by this resource; the rest of the policy file, including comments, is left intact. SSH access rules are identified by their contents, so any change to a rule replaces it. This resource must not be used together with the ` + "`tailscale_acl` or `tailscale_policy`" + ` resources, which manage the whole policy file.`
+
+func resourceACLSSH() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLSSHDescription,
+		ReadContext:   resourceACLSSHRead,
+		CreateContext: resourceACLSSHCreate,
+		DeleteContext: resourceACLSSHDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceACLSSHImport,
+		},
+		Schema: policySSHSchema(true),
+	}
+}
+
+func resourceACLSSHRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	rule, err := expandPolicySSH(resourceDataMap(d, policySSHSchema(true)))
+	if err != nil {
+		return diagnosticsError(err, "Failed to read SSH access rule")
+	}
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	_, index, err := findPolicyRule(policy, "ssh", rule)
+	if err != nil {
+		return diagnosticsError(err, "Failed to read SSH access rule")
+	}
+	if index < 0 {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourceACLSSHCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	rule, err := expandPolicySSH(resourceDataMap(d, policySSHSchema(true)))
+	if err != nil {
+		return diagnosticsError(err, "Failed to create SSH access rule")
+	}
+
+	err = updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return createPolicyRule(d, policy, "ssh", rule, "tailscale_acl_ssh")
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to create SSH access rule")
+	}
+
+	id, err := policyRuleID(rule)
+	if err != nil {
+		return diagnosticsError(err, "Failed to create SSH access rule")
+	}
+
+	d.SetId(id)
+	return resourceACLSSHRead(ctx, d, m)
+}
+
+func resourceACLSSHImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	client := m.(*tailscale.Client)
+
+	var rule tailscale.ACLSSH
+	if err := importPolicyRule(ctx, d, client, "ssh", &rule); err != nil {
+		return nil, err
+	}
+
+	props, err := flattenPolicySSH(rule)
+	if err != nil {
+		return nil, err
+	}
+	if diags := setProperties(d, props); diags.HasError() {
+		return nil, diagnosticsAsError(diags)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceACLSSHDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	rule, err := expandPolicySSH(resourceDataMap(d, policySSHSchema(true)))
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete SSH access rule")
+	}
+
+	err = updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return removePolicyRule(policy, "ssh", rule)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete SSH access rule")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testACLSSH = `
+	resource "tailscale_acl_ssh" "test_ssh" {
+		action = "accept"
+		src    = ["autogroup:member"]
+		dst    = ["autogroup:self"]
+		users  = ["autogroup:nonroot"]
+	}`
+
+func TestProvider_TailscaleACLSSH(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{"ssh": [{"action": "accept", "src": ["autogroup:member"], "dst": ["autogroup:self"], "users": ["autogroup:nonroot"]}]}`)
+			// The policy file only contains the rule once it has been created.
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/example.com/acl": {[]byte(`{}`)},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_acl_ssh.test_ssh", Config: testACLSSH},
+			testResourceDestroyed("tailscale_acl_ssh.test_ssh", testACLSSH),
+		},
+	})
+}
+
+func TestResourceACLSSHImport(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestPolicyServer(t, `{"ssh": [{"action": "check", "src": ["autogroup:member"], "dst": ["autogroup:self"], "users": ["root"], "checkPeriod": "12h"}]}`)
+
+	d := resourceACLSSH().Data(nil)
+	d.SetId("0")
+	imported, err := resourceACLSSHImport(context.Background(), d, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for attr, want := range map[string]string{
+		"action":       "check",
+		"src.0":        "autogroup:member",
+		"dst.0":        "autogroup:self",
+		"users.0":      "root",
+		"check_period": "12h0m0s",
+	} {
+		if got := imported[0].Get(attr); got != want {
+			t.Errorf("expected %s to be %q, got %q", attr, want, got)
+		}
+	}
+
+	invalid := resourceACLSSH().Data(nil)
+	invalid.SetId("first")
+	if _, err := resourceACLSSHImport(context.Background(), invalid, client); err == nil {
+		t.Error("expected an error importing an SSH access rule by a non-numeric ID")
+	}
+}
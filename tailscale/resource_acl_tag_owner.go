@@ -0,0 +1,118 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourceACLTagOwnerDescription = `The acl_tag_owner resource allows you to manage the owners of a single tag in the Tailscale policy file. See https://tailscale.com/kb/1337/acl-syntax#tag-owners for more information.
+
+Only the owners of the tag are managed by this resource; the rest of the policy file, including comments, is left intact. This resource must not be used together with the ` + "`tailscale_acl` or `tailscale_policy`" + ` resources, which manage the whole policy file.`
+
+func resourceACLTagOwner() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourceACLTagOwnerDescription,
+		ReadContext:   resourceACLTagOwnerRead,
+		CreateContext: resourceACLTagOwnerCreate,
+		UpdateContext: resourceACLTagOwnerUpdate,
+		DeleteContext: resourceACLTagOwnerDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"tag": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The tag, starting with `tag:`.",
+				ValidateFunc: validation.StringMatch(tagNameRegexp, "tags must start with `tag:`"),
+			},
+			"owners": policyStringList("The users, groups and tags that can apply the tag. If empty, only admins can apply the tag.", false),
+		},
+	}
+}
+
+func resourceACLTagOwnerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	policy, _, err := readPolicy(ctx, client)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	var owners []string
+	err = getPolicyValue(policy, &owners, []string{"tagOwners"}, d.Id())
+	if errors.Is(err, errPolicyFragmentNotFound) {
+		d.SetId("")
+		return nil
+	} else if err != nil {
+		return diagnosticsError(err, "Failed to read tag owners")
+	}
+
+	return setProperties(d, map[string]any{
+		"tag":    d.Id(),
+		"owners": owners,
+	})
+}
+
+func resourceACLTagOwnerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	tag := d.Get("tag").(string)
+	if diags := resourceACLTagOwnerSet(ctx, d, m, tag, true); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(tag)
+	return resourceACLTagOwnerRead(ctx, d, m)
+}
+
+func resourceACLTagOwnerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := resourceACLTagOwnerSet(ctx, d, m, d.Id(), false); diags.HasError() {
+		return diags
+	}
+
+	return resourceACLTagOwnerRead(ctx, d, m)
+}
+
+func resourceACLTagOwnerSet(ctx context.Context, d *schema.ResourceData, m interface{}, tag string, create bool) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	owners := expandStrings(d.Get("owners"))
+	if owners == nil {
+		owners = []string{}
+	}
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		if create {
+			return createPolicyValue(policy, owners, "tailscale_acl_tag_owner", policyImportID(d, tag), []string{"tagOwners"}, tag)
+		}
+		return setPolicyValue(policy, owners, []string{"tagOwners"}, tag)
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to set tag owners")
+	}
+
+	return nil
+}
+
+func resourceACLTagOwnerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	err := updatePolicy(ctx, client, func(policy *hujson.Value) error {
+		return removePolicyValue(policy, []string{"tagOwners"}, d.Id())
+	})
+	if err != nil {
+		return diagnosticsError(err, "Failed to delete tag owners")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testACLTagOwner = `
+	resource "tailscale_acl_tag_owner" "test_tag_owner" {
+		tag    = "tag:example"
+		owners = ["group:example"]
+	}`
+
+func TestProvider_TailscaleACLTagOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{"tagOwners": {"tag:example": ["group:example"]}}`)
+			// The policy file only contains the entry once it has been created.
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/example.com/acl": {[]byte(`{}`)},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_acl_tag_owner.test_tag_owner", Config: testACLTagOwner},
+			testResourceDestroyed("tailscale_acl_tag_owner.test_tag_owner", testACLTagOwner),
+		},
+	})
+}
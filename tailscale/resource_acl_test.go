@@ -6,8 +6,10 @@ package tailscale
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -82,7 +84,7 @@ func TestProvider_TailscaleACL(t *testing.T) {
 			testServer.ResponseCode = http.StatusOK
 			testServer.ResponseBody = nil
 		},
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			testResourceCreated("tailscale_acl.test_acl", testACL),
 			testResourceDestroyed("tailscale_acl.test_acl", testACL),
@@ -115,8 +117,8 @@ func TestProvider_TailscaleACLDiffs(t *testing.T) {
 	}
 
 	resource.Test(t, resource.TestCase{
-		IsUnitTest:        true,
-		ProviderFactories: testProviderFactories(t),
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		PreCheck: func() {
 			testServer.ResponseCode = http.StatusOK
 			testServer.ResponseBody = []byte("{}")
@@ -148,6 +150,32 @@ func TestProvider_TailscaleACLDiffs(t *testing.T) {
 	})
 }
 
+func TestPolicyConflictDiagnostics(t *testing.T) {
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = []byte(`{
+		"hosts": {"example": "100.101.102.103"},
+		// Added in the admin console.
+		"groups": {"group:example": ["user1@example.com"]},
+	}`)
+
+	diags := policyConflictDiagnostics(context.Background(), client, `{"hosts": {"example": "100.101.102.103"}}`, errors.New("precondition failed (412)"))
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+
+	for _, want := range []string{
+		"--- expected",
+		"+++ live",
+		`+	// Added in the admin console.`,
+		`+	"groups": {"group:example": ["user1@example.com"]},`,
+	} {
+		if !strings.Contains(diags[0].Detail, want) {
+			t.Errorf("expected diagnostic detail to contain %q, got:\n%s", want, diags[0].Detail)
+		}
+	}
+}
+
 func TestAccACL(t *testing.T) {
 	const resourceName = "tailscale_acl.test_acl"
 
@@ -183,8 +211,8 @@ func TestAccACL(t *testing.T) {
 		}`
 
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories(t),
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: testACLCreate,
@@ -246,8 +274,8 @@ func TestAccACL_resetOnDestroy(t *testing.T) {
 		}`
 
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories(t),
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProviderFactories(t),
 		CheckDestroy: checkResourceDestroyed(resourceName, func(client *tailscale.Client, rs *terraform.ResourceState) error {
 			aclAfterDestroy, err := client.PolicyFile().Raw(context.Background())
 			if err != nil {
@@ -41,7 +41,7 @@ func resourceDeviceAuthorizationRead(ctx context.Context, d *schema.ResourceData
 	client := m.(*tailscale.Client)
 	deviceID := d.Id()
 
-	device, err := client.Devices().Get(ctx, deviceID)
+	device, err := getDevice(ctx, client, deviceID)
 	if err != nil {
 		return diagnosticsError(err, "Failed to fetch device")
 	}
@@ -69,6 +69,7 @@ func resourceDeviceAuthorizationCreate(ctx context.Context, d *schema.ResourceDa
 		if err := client.Devices().SetAuthorized(ctx, deviceID, true); err != nil {
 			return diagnosticsError(err, "Failed to authorize device")
 		}
+		invalidateDevice(client, deviceID)
 	}
 
 	d.SetId(deviceID)
@@ -79,7 +80,7 @@ func resourceDeviceAuthorizationUpdate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*tailscale.Client)
 	deviceID := d.Get("device_id").(string)
 
-	device, err := client.Devices().Get(ctx, deviceID)
+	device, err := getDevice(ctx, client, deviceID)
 	if err != nil {
 		return diagnosticsError(err, "Failed to fetch device")
 	}
@@ -94,6 +95,7 @@ func resourceDeviceAuthorizationUpdate(ctx context.Context, d *schema.ResourceDa
 	if err = client.Devices().SetAuthorized(ctx, deviceID, true); err != nil {
 		return diagnosticsError(err, "Failed to authorize device")
 	}
+	invalidateDevice(client, deviceID)
 
 	d.Set("authorized", true)
 	return resourceDeviceAuthorizationRead(ctx, d, m)
@@ -50,6 +50,7 @@ func resourceDeviceKeyCreate(ctx context.Context, d *schema.ResourceData, m inte
 	if err := client.Devices().SetKey(ctx, deviceID, key); err != nil {
 		return diagnosticsError(err, "failed to update device key")
 	}
+	invalidateDevice(client, deviceID)
 
 	d.SetId(deviceID)
 	return resourceDeviceKeyRead(ctx, d, m)
@@ -64,6 +65,7 @@ func resourceDeviceKeyDelete(ctx context.Context, d *schema.ResourceData, m inte
 	if err := client.Devices().SetKey(ctx, deviceID, key); err != nil {
 		return diagnosticsError(err, "failed to update device key")
 	}
+	invalidateDevice(client, deviceID)
 
 	return nil
 }
@@ -72,7 +74,7 @@ func resourceDeviceKeyRead(ctx context.Context, d *schema.ResourceData, m interf
 	client := m.(*tailscale.Client)
 	deviceID := d.Id()
 
-	device, err := client.Devices().Get(ctx, deviceID)
+	device, err := getDevice(ctx, client, deviceID)
 	if err != nil {
 		return diagnosticsError(err, "Failed to fetch devices")
 	}
@@ -106,6 +108,7 @@ func resourceDeviceKeyUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	if err := client.Devices().SetKey(ctx, deviceID, key); err != nil {
 		return diagnosticsError(err, "failed to update device key")
 	}
+	invalidateDevice(client, deviceID)
 
 	return resourceDeviceKeyRead(ctx, d, m)
 }
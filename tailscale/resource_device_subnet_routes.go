@@ -94,6 +94,7 @@ func resourceDeviceSubnetRoutesCreate(ctx context.Context, d *schema.ResourceDat
 	if err := client.Devices().SetSubnetRoutes(ctx, deviceID, subnetRoutes); err != nil {
 		return diagnosticsError(err, "Failed to set device subnet routes")
 	}
+	invalidateDevice(client, deviceID)
 
 	d.SetId(createUUID())
 	return resourceDeviceSubnetRoutesRead(ctx, d, m)
@@ -112,6 +113,7 @@ func resourceDeviceSubnetRoutesUpdate(ctx context.Context, d *schema.ResourceDat
 	if err := client.Devices().SetSubnetRoutes(ctx, deviceID, subnetRoutes); err != nil {
 		return diagnosticsError(err, "Failed to set device subnet routes")
 	}
+	invalidateDevice(client, deviceID)
 
 	return resourceDeviceSubnetRoutesRead(ctx, d, m)
 }
@@ -123,6 +125,7 @@ func resourceDeviceSubnetRoutesDelete(ctx context.Context, d *schema.ResourceDat
 	if err := client.Devices().SetSubnetRoutes(ctx, deviceID, []string{}); err != nil {
 		return diagnosticsError(err, "Failed to set device subnet routes")
 	}
+	invalidateDevice(client, deviceID)
 
 	return nil
 }
@@ -5,6 +5,7 @@ package tailscale
 
 import (
 	"context"
+	"errors"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -30,6 +31,7 @@ func resourceDeviceTags() *schema.Resource {
 		CreateContext: resourceDeviceTagsSet,
 		UpdateContext: resourceDeviceTagsSet,
 		DeleteContext: deleteContext,
+		CustomizeDiff: resourceDeviceTagsDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -44,8 +46,9 @@ func resourceDeviceTags() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Required:    true,
-				Description: "The tags to apply to the device",
+				Optional:    true,
+				Computed:    true,
+				Description: "The tags to apply to the device. Required unless the provider sets `default_tags`.",
 			},
 		},
 	}
@@ -55,7 +58,7 @@ func resourceDeviceTagsRead(ctx context.Context, d *schema.ResourceData, m inter
 	client := m.(*tailscale.Client)
 	deviceID := d.Id()
 
-	device, err := client.Devices().Get(ctx, deviceID)
+	device, err := getDevice(ctx, client, deviceID)
 	if err != nil {
 		return diagnosticsError(err, "Failed to fetch device")
 	}
@@ -74,6 +77,24 @@ func resourceDeviceTagsRead(ctx context.Context, d *schema.ResourceData, m inter
 	return nil
 }
 
+// resourceDeviceTagsDiff requires the tags to be configured unless the
+// provider sets default tags. The tags are computed so that the default tags
+// can be planned, which would otherwise make them optional.
+func resourceDeviceTagsDiff(_ context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if policy := tagPolicyFor(m.(*tailscale.Client)); policy != nil && len(policy.defaultTags) > 0 {
+		return nil
+	}
+
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	if config.GetAttr("tags").IsNull() {
+		return errors.New("\"tags\" is required unless the provider sets default_tags")
+	}
+	return nil
+}
+
 func resourceDeviceTagsSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*tailscale.Client)
 	deviceID := d.Get("device_id").(string)
@@ -87,6 +108,7 @@ func resourceDeviceTagsSet(ctx context.Context, d *schema.ResourceData, m interf
 	if err := client.Devices().SetTags(ctx, deviceID, tags); err != nil {
 		return diagnosticsError(err, "Failed to set device tags")
 	}
+	invalidateDevice(client, deviceID)
 
 	d.SetId(deviceID)
 	return resourceDeviceTagsRead(ctx, d, m)
@@ -99,6 +121,7 @@ func resourceDeviceTagsDelete(ctx context.Context, d *schema.ResourceData, m int
 	if err := client.Devices().SetTags(ctx, deviceID, []string{}); err != nil {
 		return diagnosticsError(err, "Failed to set device tags")
 	}
+	invalidateDevice(client, deviceID)
 
 	d.SetId(deviceID)
 	return nil
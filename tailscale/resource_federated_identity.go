@@ -51,6 +51,7 @@ func resourceFederatedIdentity() *schema.Resource {
 					Type: schema.TypeString,
 				},
 				Optional:    true,
+				Computed:    true,
 				Description: "A list of tags that access tokens generated for the federated identity will be able to assign to devices. Mandatory if the scopes include \"devices:core\" or \"auth_keys\".",
 			},
 			"id": {
@@ -67,10 +67,25 @@ func resourceLogstreamConfiguration() *schema.Resource {
 				Default:     "user",
 			},
 			"token": {
-				Type:        schema.TypeString,
-				Description: "The token/password with which log streams to this endpoint should be authenticated, required unless destination_type is 's3'.",
-				Optional:    true,
-				Sensitive:   true,
+				Type:          schema.TypeString,
+				Description:   "The token/password with which log streams to this endpoint should be authenticated, required unless destination_type is 's3'. Stored in the state, use `token_wo` instead to keep it out of the state.",
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"token_wo"},
+			},
+			"token_wo": {
+				Type:          schema.TypeString,
+				Description:   "The write-only variant of `token`, which is sent to the API but never stored in the state. Requires Terraform 1.11 or later. Changing only `token_wo` does not update the resource, change `token_wo_version` to send the new value.",
+				Optional:      true,
+				WriteOnly:     true,
+				Sensitive:     true,
+				ConflictsWith: []string{"token"},
+			},
+			"token_wo_version": {
+				Type:         schema.TypeInt,
+				Description:  "The version of `token_wo`. Change it to send a new value of `token_wo` to the API.",
+				Optional:     true,
+				RequiredWith: []string{"token_wo"},
 			},
 			"upload_period_minutes": {
 				Type:        schema.TypeInt,
@@ -124,10 +139,25 @@ func resourceLogstreamConfiguration() *schema.Resource {
 				Optional:    true,
 			},
 			"s3_secret_access_key": {
-				Type:        schema.TypeString,
-				Description: "The S3 secret access key. Required if destination_type is 's3' and s3_authentication_type is 'accesskey'.",
-				Optional:    true,
-				Sensitive:   true,
+				Type:          schema.TypeString,
+				Description:   "The S3 secret access key. Required if destination_type is 's3' and s3_authentication_type is 'accesskey'. Stored in the state, use `s3_secret_access_key_wo` instead to keep it out of the state.",
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"s3_secret_access_key_wo"},
+			},
+			"s3_secret_access_key_wo": {
+				Type:          schema.TypeString,
+				Description:   "The write-only variant of `s3_secret_access_key`, which is sent to the API but never stored in the state. Requires Terraform 1.11 or later. Changing only `s3_secret_access_key_wo` does not update the resource, change `s3_secret_access_key_wo_version` to send the new value.",
+				Optional:      true,
+				WriteOnly:     true,
+				Sensitive:     true,
+				ConflictsWith: []string{"s3_secret_access_key"},
+			},
+			"s3_secret_access_key_wo_version": {
+				Type:         schema.TypeInt,
+				Description:  "The version of `s3_secret_access_key_wo`. Change it to send a new value of `s3_secret_access_key_wo` to the API.",
+				Optional:     true,
+				RequiredWith: []string{"s3_secret_access_key_wo"},
 			},
 			"s3_role_arn": {
 				Type:        schema.TypeString,
@@ -193,7 +223,7 @@ func resourceLogstreamConfigurationCreate(ctx context.Context, d *schema.Resourc
 	destinationType := d.Get("destination_type").(string)
 	endpointURL := d.Get("url").(string)
 	user := d.Get("user").(string)
-	token := d.Get("token").(string)
+	token := secretString(d, "token")
 	uploadPeriodMinutes := d.Get("upload_period_minutes").(int)
 	compressionFormat := d.Get("compression_format").(string)
 	s3Bucket := d.Get("s3_bucket").(string)
@@ -201,7 +231,7 @@ func resourceLogstreamConfigurationCreate(ctx context.Context, d *schema.Resourc
 	s3KeyPrefix := d.Get("s3_key_prefix").(string)
 	s3AuthenticationType := tailscale.S3AuthenticationType(d.Get("s3_authentication_type").(string))
 	s3AccessKeyID := d.Get("s3_access_key_id").(string)
-	s3SecretAccessKey := d.Get("s3_secret_access_key").(string)
+	s3SecretAccessKey := secretString(d, "s3_secret_access_key")
 	s3RoleARN := d.Get("s3_role_arn").(string)
 	s3ExternalID := d.Get("s3_external_id").(string)
 	gcsCredentials := d.Get("gcs_credentials").(string)
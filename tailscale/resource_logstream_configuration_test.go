@@ -5,10 +5,15 @@ package tailscale
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -188,8 +193,8 @@ func TestAccTailscaleLogstreamConfiguration(t *testing.T) {
 	}
 
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories(t),
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProviderFactories(t),
 		CheckDestroy: checkResourceDestroyed(resourceName, func(client *tailscale.Client, rs *terraform.ResourceState) error {
 			_, err := client.Logging().LogstreamConfiguration(context.Background(), tailscale.LogType(rs.Primary.ID))
 			if err == nil {
@@ -353,3 +358,40 @@ func TestAccTailscaleLogstreamConfiguration(t *testing.T) {
 		},
 	})
 }
+
+func TestLogstreamConfigurationWriteOnlySecrets(t *testing.T) {
+	var sent tailscale.SetLogstreamConfigurationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+		}
+		_ = json.NewEncoder(w).Encode(tailscale.LogstreamConfiguration{
+			LogType:         tailscale.LogTypeNetwork,
+			DestinationType: tailscale.LogstreamS3Endpoint,
+		})
+	}))
+	t.Cleanup(server.Close)
+	baseURL, _ := url.Parse(server.URL)
+	client := &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}
+
+	// Write-only arguments are only ever in the configuration.
+	d := resourceLogstreamConfiguration().Data(&terraform.InstanceState{
+		Attributes: map[string]string{"log_type": "network", "destination_type": "s3"},
+		RawConfig: cty.ObjectVal(map[string]cty.Value{
+			"token_wo":                cty.StringVal("some-token"),
+			"s3_secret_access_key_wo": cty.StringVal("example-secret-access-key"),
+		}),
+	})
+	if diags := resourceLogstreamConfigurationCreate(context.Background(), d, client); diags.HasError() {
+		t.Fatal(diagnosticsAsError(diags))
+	}
+
+	if sent.Token != "some-token" || sent.S3SecretAccessKey != "example-secret-access-key" {
+		t.Errorf("expected the write-only secrets to be sent, got %q and %q", sent.Token, sent.S3SecretAccessKey)
+	}
+	for _, key := range []string{"token", "s3_secret_access_key"} {
+		if secret := d.Get(key).(string); secret != "" {
+			t.Errorf("expected %s not to be stored, got %q", key, secret)
+		}
+	}
+}
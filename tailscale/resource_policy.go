@@ -0,0 +1,800 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const resourcePolicyDescription = `The policy resource allows you to configure a Tailscale policy file using typed blocks instead of a JSON or HuJSON string. The blocks are rendered into a canonical policy file which is then applied to the tailnet. See https://tailscale.com/kb/1395/tailnet-policy-file for more information.
+
+Like the acl resource, this resource completely overwrites existing policy file contents for a given tailnet. Sections of the policy file that cannot be expressed using the blocks below are removed when the policy file is updated. Do not use this resource together with the acl resource for the same tailnet.
+
+If tests are defined (the "tests" blocks), policy file validation will occur before creation and update operations are applied.`
+
+var (
+	groupNameRegexp = regexp.MustCompile(`^group:.+`)
+	tagNameRegexp   = regexp.MustCompile(`^tag:.+`)
+)
+
+// policyGetter is implemented by both [schema.ResourceData] and [schema.ResourceDiff].
+type policyGetter interface {
+	Get(key string) interface{}
+}
+
+func resourcePolicy() *schema.Resource {
+	return &schema.Resource{
+		Description:   resourcePolicyDescription,
+		ReadContext:   resourcePolicyRead,
+		CreateContext: resourcePolicyCreate,
+		UpdateContext: resourcePolicyUpdate,
+		DeleteContext: resourcePolicyDelete,
+		CustomizeDiff: resourcePolicyDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"acls": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Legacy access rules. Consider using `grants` instead. See https://tailscale.com/kb/1337/policy-syntax#acls for more information.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "accept",
+							Description:  "The action to take. The only supported value is `accept`.",
+							ValidateFunc: validation.StringInSlice([]string{"accept"}, false),
+						},
+						"src":         policyStringList("The sources to allow access from.", true),
+						"dst":         policyStringList("The destinations and ports to allow access to, in the form `host:ports`.", true),
+						"proto":       {Type: schema.TypeString, Optional: true, Description: "The IP protocol this rule applies to."},
+						"src_posture": policyStringList("Device posture conditions that sources must satisfy.", false),
+					},
+				},
+			},
+			"grants": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Access rules granting network and application capabilities. See https://tailscale.com/kb/1324/grants for more information.",
+				Elem: &schema.Resource{
+					Schema: policyGrantSchema(false),
+				},
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Named groups of users. See https://tailscale.com/kb/1337/policy-syntax#groups for more information.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The name of the group, including the `group:` prefix.",
+							ValidateFunc: validation.StringMatch(groupNameRegexp, "group names must start with `group:`"),
+						},
+						"members": policyStringList("The members of the group.", false),
+					},
+				},
+			},
+			"tag_owners": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The users, groups and tags that may apply each tag. See https://tailscale.com/kb/1337/policy-syntax#tag-owners for more information.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The tag, including the `tag:` prefix.",
+							ValidateFunc: validation.StringMatch(tagNameRegexp, "tags must start with `tag:`"),
+						},
+						"owners": policyStringList("The owners of the tag.", false),
+					},
+				},
+			},
+			"hosts": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Human-friendly aliases for IP addresses and CIDR ranges.",
+			},
+			"auto_approvers": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The users, groups and tags that may advertise subnet routes and exit nodes without further approval. See https://tailscale.com/kb/1337/policy-syntax#autoapprovers for more information.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"routes": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "The approvers for each subnet route.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"cidr": {
+										Type:         schema.TypeString,
+										Required:     true,
+										Description:  "The subnet route.",
+										ValidateFunc: validation.IsCIDR,
+									},
+									"approvers": policyStringList("The users, groups and tags that may advertise the route.", true),
+								},
+							},
+						},
+						"exit_node": policyStringList("The users, groups and tags that may advertise exit nodes.", false),
+					},
+				},
+			},
+			"ssh": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Tailscale SSH access rules. See https://tailscale.com/kb/1337/policy-syntax#ssh for more information.",
+				Elem: &schema.Resource{
+					Schema: policySSHSchema(false),
+				},
+			},
+			"node_attrs": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Attributes applied to devices. See https://tailscale.com/kb/1337/policy-syntax#nodeattrs for more information.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"target":  policyStringList("The devices the attributes apply to.", true),
+						"attr":    policyStringList("The attributes to apply.", false),
+						"ip_pool": policyStringList("The IP pools to allocate addresses from.", false),
+						"app": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "The application configuration, as a JSON object (for example using `jsonencode`).",
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+						},
+					},
+				},
+			},
+			"tests": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Tests that are checked whenever the policy file is changed. See https://tailscale.com/kb/1337/policy-syntax#tests for more information.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"src":    {Type: schema.TypeString, Required: true, Description: "The source to test access from."},
+						"accept": policyStringList("Destinations, in the form `host:port`, that must be accessible.", false),
+						"deny":   policyStringList("Destinations, in the form `host:port`, that must not be accessible.", false),
+					},
+				},
+			},
+			"policy": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The policy file rendered from the blocks of this resource, as a HuJSON string.",
+			},
+			"overwrite_existing_content": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If true, will skip requirement to import the policy file before allowing changes. Be careful, can cause the policy file to be overwritten",
+			},
+			"reset_acl_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If true, will reset the policy file for the Tailnet to the default when this resource is destroyed",
+			},
+			"overwrite_concurrent_changes": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If true, updates will overwrite changes made to the policy file outside of Terraform since it was last read. By default, such updates fail and show the changes that would have been lost",
+			},
+			"etag": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ETag of the policy file when it was last read, used to detect changes made outside of Terraform",
+			},
+		},
+	}
+}
+
+// policyStringList returns the schema for an optional or required list of strings.
+func policyStringList(description string, required bool) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Required:    required,
+		Optional:    !required,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: description,
+	}
+}
+
+// policyGrantSchema returns the schema for a grant. When forceNew is true,
+// changes to any attribute of the grant require replacement.
+func policyGrantSchema(forceNew bool) map[string]*schema.Schema {
+	return withForceNew(forceNew, map[string]*schema.Schema{
+		"src":         policyStringList("The sources this grant applies to.", true),
+		"dst":         policyStringList("The destinations this grant applies to.", true),
+		"ip":          policyStringList("The network capabilities (protocols and ports) granted.", false),
+		"via":         policyStringList("The tagged routers or exit nodes the traffic must be routed through.", false),
+		"src_posture": policyStringList("Device posture conditions that sources must satisfy.", false),
+		"app": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "The application capabilities granted, as a JSON object (for example using `jsonencode`).",
+			ValidateFunc:     validation.StringIsJSON,
+			DiffSuppressFunc: suppressEquivalentJSON,
+		},
+	})
+}
+
+// policySSHSchema returns the schema for an SSH access rule. When forceNew is
+// true, changes to any attribute of the rule require replacement.
+func policySSHSchema(forceNew bool) map[string]*schema.Schema {
+	return withForceNew(forceNew, map[string]*schema.Schema{
+		"action": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The action to take. Either `accept` or `check`.",
+			ValidateFunc: validation.StringInSlice([]string{"accept", "check"}, false),
+		},
+		"src":   policyStringList("The sources to allow SSH access from.", true),
+		"dst":   policyStringList("The destinations to allow SSH access to.", true),
+		"users": policyStringList("The SSH users that can be used.", true),
+		"check_period": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "How often to re-check access when `action` is `check`, as a duration (for example `12h`) or `always`.",
+			ValidateFunc:     validateSSHCheckPeriod,
+			DiffSuppressFunc: suppressEquivalentSSHCheckPeriod,
+		},
+		"recorder":         policyStringList("The tags of session recorders to send SSH session recordings to.", false),
+		"enforce_recorder": {Type: schema.TypeBool, Optional: true, Description: "Whether to block SSH sessions if recording fails."},
+	})
+}
+
+func withForceNew(forceNew bool, s map[string]*schema.Schema) map[string]*schema.Schema {
+	for _, v := range s {
+		v.ForceNew = forceNew
+	}
+	return s
+}
+
+func validateSSHCheckPeriod(i interface{}, k string) ([]string, []error) {
+	var period tailscale.SSHCheckPeriod
+	if err := period.UnmarshalText([]byte(i.(string))); err != nil {
+		return nil, []error{fmt.Errorf("%s is not a valid duration: %w", k, err)}
+	}
+	return nil, nil
+}
+
+func suppressEquivalentSSHCheckPeriod(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	var old, new tailscale.SSHCheckPeriod
+	if old.UnmarshalText([]byte(oldValue)) != nil || new.UnmarshalText([]byte(newValue)) != nil {
+		return false
+	}
+	return old == new
+}
+
+// equivalentPolicies reports whether two HuJSON policy files have the same
+// contents, ignoring comments and formatting.
+func equivalentPolicies(a, b string) bool {
+	aJSON, aErr := hujson.Standardize([]byte(a))
+	bJSON, bErr := hujson.Standardize([]byte(b))
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	return suppressEquivalentJSON("", string(aJSON), string(bJSON), nil)
+}
+
+func suppressEquivalentJSON(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	var old, new any
+	if json.Unmarshal([]byte(oldValue), &old) != nil || json.Unmarshal([]byte(newValue), &new) != nil {
+		return false
+	}
+	oldJSON, _ := json.Marshal(old)
+	newJSON, _ := json.Marshal(new)
+	return string(oldJSON) == string(newJSON)
+}
+
+// resourcePolicyDiff renders the policy file from the configured blocks so
+// that it is shown in the plan, and validates it using the API.
+func resourcePolicyDiff(ctx context.Context, rd *schema.ResourceDiff, m interface{}) error {
+	// If any of the blocks are only known after apply, the policy file can't
+	// be rendered yet.
+	if !rd.GetRawConfig().IsWhollyKnown() {
+		if err := rd.SetNewComputed("policy"); err != nil {
+			return err
+		}
+		return setPolicyETagComputed(rd)
+	}
+
+	policy, err := expandPolicy(rd)
+	if err != nil {
+		return err
+	}
+
+	if !equivalentPolicies(rd.Get("policy").(string), policy) {
+		if err := rd.SetNew("policy", policy); err != nil {
+			return err
+		}
+		if err := setPolicyETagComputed(rd); err != nil {
+			return err
+		}
+	}
+
+	return validatePolicy(ctx, m.(*tailscale.Client), policy)
+}
+
+// setPolicyETagComputed marks the ETag as changing when the policy file of
+// an existing resource is updated.
+func setPolicyETagComputed(rd *schema.ResourceDiff) error {
+	if rd.Id() == "" {
+		return nil
+	}
+	return rd.SetNewComputed("etag")
+}
+
+func resourcePolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+	raw, err := client.PolicyFile().Raw(ctx)
+	if err != nil {
+		return diagnosticsError(err, "Failed to fetch policy file")
+	}
+
+	standardized, err := hujson.Standardize([]byte(raw.HuJSON))
+	if err != nil {
+		return diagnosticsError(err, "Failed to parse policy file as HuJSON")
+	}
+
+	var acl tailscale.ACL
+	if err := json.Unmarshal(standardized, &acl); err != nil {
+		return diagnosticsError(err, "Failed to parse policy file")
+	}
+
+	props, err := flattenPolicy(d, &acl)
+	if err != nil {
+		return diagnosticsError(err, "Failed to read policy file")
+	}
+
+	formatted, err := hujson.Format([]byte(raw.HuJSON))
+	if err != nil {
+		return diagnosticsError(err, "Failed to format policy file")
+	}
+	props["policy"] = string(formatted)
+	props["etag"] = raw.ETag
+
+	return setProperties(d, props)
+}
+
+func resourcePolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	policy, err := expandPolicy(d)
+	if err != nil {
+		return diagnosticsError(err, "Failed to render policy file")
+	}
+
+	if diags := setInitialPolicy(ctx, client, "tailscale_policy", policy, d.Get("overwrite_existing_content").(bool)); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(createUUID())
+	return resourcePolicyRead(ctx, d, m)
+}
+
+func resourcePolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*tailscale.Client)
+
+	policy, err := expandPolicy(d)
+	if err != nil {
+		return diagnosticsError(err, "Failed to render policy file")
+	}
+
+	// Only update the policy file if it has not changed since it was last read,
+	// unless explicitly configured otherwise.
+	var etag string
+	if !d.Get("overwrite_concurrent_changes").(bool) {
+		oldETag, _ := d.GetChange("etag")
+		etag = oldETag.(string)
+	}
+
+	if err := client.PolicyFile().Set(ctx, policy, etag); err != nil {
+		if isPreconditionFailed(err) {
+			expected, _ := d.GetChange("policy")
+			return policyConflictDiagnostics(ctx, client, expected.(string), err)
+		}
+		return diagnosticsError(err, "Failed to set policy file")
+	}
+
+	return resourcePolicyRead(ctx, d, m)
+}
+
+func resourcePolicyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// As with tailscale_acl, deleting the resource only removes it from
+	// Terraform state unless a reset was requested.
+	if !d.Get("reset_acl_on_destroy").(bool) {
+		return nil
+	}
+
+	client := m.(*tailscale.Client)
+	if err := client.PolicyFile().Set(ctx, "", ""); err != nil {
+		return diagnosticsError(err, "Failed to reset policy file")
+	}
+
+	return nil
+}
+
+// expandPolicy renders the policy blocks of the resource as a canonical
+// HuJSON policy file.
+func expandPolicy(d policyGetter) (string, error) {
+	var acl tailscale.ACL
+
+	for _, item := range d.Get("acls").([]interface{}) {
+		rule := blockMap(item)
+		acl.ACLs = append(acl.ACLs, tailscale.ACLEntry{
+			Action:        rule["action"].(string),
+			Source:        expandStrings(rule["src"]),
+			Destination:   expandStrings(rule["dst"]),
+			Protocol:      rule["proto"].(string),
+			SourcePosture: expandStrings(rule["src_posture"]),
+		})
+	}
+
+	for _, item := range d.Get("grants").([]interface{}) {
+		grant, err := expandPolicyGrant(blockMap(item))
+		if err != nil {
+			return "", err
+		}
+		acl.Grants = append(acl.Grants, grant)
+	}
+
+	groups, err := expandNamedLists(d.Get("groups").([]interface{}), "name", "members")
+	if err != nil {
+		return "", err
+	}
+	acl.Groups = groups
+
+	tagOwners, err := expandNamedLists(d.Get("tag_owners").([]interface{}), "tag", "owners")
+	if err != nil {
+		return "", err
+	}
+	acl.TagOwners = tagOwners
+
+	for name, address := range d.Get("hosts").(map[string]interface{}) {
+		if acl.Hosts == nil {
+			acl.Hosts = make(map[string]string)
+		}
+		acl.Hosts[name] = address.(string)
+	}
+
+	if approvers := d.Get("auto_approvers").([]interface{}); len(approvers) > 0 {
+		approver := blockMap(approvers[0])
+		routes, err := expandNamedLists(listOrNil(approver["routes"]), "cidr", "approvers")
+		if err != nil {
+			return "", err
+		}
+		exitNode := expandStrings(approver["exit_node"])
+		if len(routes) > 0 || len(exitNode) > 0 {
+			acl.AutoApprovers = &tailscale.ACLAutoApprovers{
+				Routes:   routes,
+				ExitNode: exitNode,
+			}
+		}
+	}
+
+	for _, item := range d.Get("ssh").([]interface{}) {
+		ssh, err := expandPolicySSH(blockMap(item))
+		if err != nil {
+			return "", err
+		}
+		acl.SSH = append(acl.SSH, ssh)
+	}
+
+	for _, item := range d.Get("node_attrs").([]interface{}) {
+		attr := blockMap(item)
+		nodeAttr := tailscale.NodeAttrGrant{
+			Target: expandStrings(attr["target"]),
+			Attr:   expandStrings(attr["attr"]),
+			IPPool: expandStrings(attr["ip_pool"]),
+		}
+		if app := attr["app"].(string); app != "" {
+			if err := json.Unmarshal([]byte(app), &nodeAttr.App); err != nil {
+				return "", fmt.Errorf("invalid node_attrs app: %w", err)
+			}
+		}
+		acl.NodeAttrs = append(acl.NodeAttrs, nodeAttr)
+	}
+
+	for _, item := range d.Get("tests").([]interface{}) {
+		test := blockMap(item)
+		acl.Tests = append(acl.Tests, tailscale.ACLTest{
+			Source: test["src"].(string),
+			Accept: expandStrings(test["accept"]),
+			Deny:   expandStrings(test["deny"]),
+		})
+	}
+
+	policy, err := json.MarshalIndent(acl, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	formatted, err := hujson.Format(policy)
+	if err != nil {
+		return "", err
+	}
+
+	return string(formatted), nil
+}
+
+// flattenPolicy converts the given policy file into the properties of the
+// resource. Groups, tag owners and auto approver routes are kept in the order
+// in which they currently appear in d, to avoid spurious diffs.
+func flattenPolicy(d policyGetter, acl *tailscale.ACL) (map[string]any, error) {
+	acls := make([]map[string]any, 0, len(acl.ACLs))
+	for _, rule := range acl.ACLs {
+		action := rule.Action
+		if action == "" {
+			action = "accept"
+		}
+		// Older policy files use "users" and "ports" in place of "src" and "dst".
+		src, dst := rule.Source, rule.Destination
+		if len(src) == 0 {
+			src = rule.Users
+		}
+		if len(dst) == 0 {
+			dst = rule.Ports
+		}
+		acls = append(acls, map[string]any{
+			"action":      action,
+			"src":         src,
+			"dst":         dst,
+			"proto":       rule.Protocol,
+			"src_posture": rule.SourcePosture,
+		})
+	}
+
+	grants := make([]map[string]any, 0, len(acl.Grants))
+	for _, grant := range acl.Grants {
+		props, err := flattenPolicyGrant(grant)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, props)
+	}
+
+	var autoApprovers []map[string]any
+	if acl.AutoApprovers != nil {
+		var existingRoutes []interface{}
+		if existing := d.Get("auto_approvers").([]interface{}); len(existing) > 0 {
+			existingRoutes = listOrNil(blockMap(existing[0])["routes"])
+		}
+		autoApprovers = []map[string]any{{
+			"routes":    flattenNamedLists(existingRoutes, acl.AutoApprovers.Routes, "cidr", "approvers"),
+			"exit_node": acl.AutoApprovers.ExitNode,
+		}}
+	}
+
+	ssh := make([]map[string]any, 0, len(acl.SSH))
+	for _, rule := range acl.SSH {
+		props, err := flattenPolicySSH(rule)
+		if err != nil {
+			return nil, err
+		}
+		ssh = append(ssh, props)
+	}
+
+	nodeAttrs := make([]map[string]any, 0, len(acl.NodeAttrs))
+	for _, attr := range acl.NodeAttrs {
+		app, err := marshalOptionalJSON(len(attr.App) > 0, attr.App)
+		if err != nil {
+			return nil, err
+		}
+		nodeAttrs = append(nodeAttrs, map[string]any{
+			"target":  attr.Target,
+			"attr":    attr.Attr,
+			"ip_pool": attr.IPPool,
+			"app":     app,
+		})
+	}
+
+	tests := make([]map[string]any, 0, len(acl.Tests))
+	for _, test := range acl.Tests {
+		// Older policy files use "user" and "allow" in place of "src" and "accept".
+		src, accept := test.Source, test.Accept
+		if src == "" {
+			src = test.User
+		}
+		if len(accept) == 0 {
+			accept = test.Allow
+		}
+		tests = append(tests, map[string]any{
+			"src":    src,
+			"accept": accept,
+			"deny":   test.Deny,
+		})
+	}
+
+	return map[string]any{
+		"acls":           acls,
+		"grants":         grants,
+		"groups":         flattenNamedLists(d.Get("groups").([]interface{}), acl.Groups, "name", "members"),
+		"tag_owners":     flattenNamedLists(d.Get("tag_owners").([]interface{}), acl.TagOwners, "tag", "owners"),
+		"hosts":          acl.Hosts,
+		"auto_approvers": autoApprovers,
+		"ssh":            ssh,
+		"node_attrs":     nodeAttrs,
+		"tests":          tests,
+	}, nil
+}
+
+// expandPolicyGrant converts the attributes of a grant block into a grant.
+func expandPolicyGrant(grant map[string]interface{}) (tailscale.Grant, error) {
+	g := tailscale.Grant{
+		Source:      expandStrings(grant["src"]),
+		Destination: expandStrings(grant["dst"]),
+		IP:          expandStrings(grant["ip"]),
+		Via:         expandStrings(grant["via"]),
+		SrcPosture:  expandStrings(grant["src_posture"]),
+	}
+	if app, _ := grant["app"].(string); app != "" {
+		if err := json.Unmarshal([]byte(app), &g.App); err != nil {
+			return g, fmt.Errorf("invalid grant app: %w", err)
+		}
+	}
+	return g, nil
+}
+
+func flattenPolicyGrant(grant tailscale.Grant) (map[string]any, error) {
+	app, err := marshalOptionalJSON(len(grant.App) > 0, grant.App)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"src":         grant.Source,
+		"dst":         grant.Destination,
+		"ip":          grant.IP,
+		"via":         grant.Via,
+		"src_posture": grant.SrcPosture,
+		"app":         app,
+	}, nil
+}
+
+// expandPolicySSH converts the attributes of an SSH block into an SSH rule.
+func expandPolicySSH(rule map[string]interface{}) (tailscale.ACLSSH, error) {
+	ssh := tailscale.ACLSSH{
+		Action:      rule["action"].(string),
+		Source:      expandStrings(rule["src"]),
+		Destination: expandStrings(rule["dst"]),
+		Users:       expandStrings(rule["users"]),
+		Recorder:    expandStrings(rule["recorder"]),
+	}
+	ssh.EnforceRecorder, _ = rule["enforce_recorder"].(bool)
+	if period, _ := rule["check_period"].(string); period != "" {
+		if err := ssh.CheckPeriod.UnmarshalText([]byte(period)); err != nil {
+			return ssh, fmt.Errorf("invalid ssh check_period: %w", err)
+		}
+	}
+	return ssh, nil
+}
+
+func flattenPolicySSH(rule tailscale.ACLSSH) (map[string]any, error) {
+	var period string
+	if rule.CheckPeriod != 0 {
+		text, err := rule.CheckPeriod.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		period = string(text)
+	}
+	return map[string]any{
+		"action":           rule.Action,
+		"src":              rule.Source,
+		"dst":              rule.Destination,
+		"users":            rule.Users,
+		"check_period":     period,
+		"recorder":         rule.Recorder,
+		"enforce_recorder": rule.EnforceRecorder,
+	}, nil
+}
+
+// expandNamedLists converts a list of blocks with a name and a list of values
+// into a map, as used for groups and tag owners in the policy file.
+func expandNamedLists(items []interface{}, nameKey, valuesKey string) (map[string][]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	out := make(map[string][]string, len(items))
+	for _, item := range items {
+		block := blockMap(item)
+		name := block[nameKey].(string)
+		if _, ok := out[name]; ok {
+			return nil, fmt.Errorf("duplicate %s %q", nameKey, name)
+		}
+		out[name] = expandStrings(block[valuesKey])
+		if out[name] == nil {
+			out[name] = []string{}
+		}
+	}
+	return out, nil
+}
+
+// flattenNamedLists is the inverse of expandNamedLists. Entries are returned in
+// the order in which they appear in existing, followed by any new entries in
+// alphabetical order.
+func flattenNamedLists(existing []interface{}, values map[string][]string, nameKey, valuesKey string) []map[string]any {
+	names := make([]string, 0, len(values))
+	for _, item := range existing {
+		name, _ := blockMap(item)[nameKey].(string)
+		if _, ok := values[name]; ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var added []string
+	for name := range values {
+		if !slices.Contains(names, name) {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	names = append(names, added...)
+
+	out := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		out = append(out, map[string]any{
+			nameKey:   name,
+			valuesKey: values[name],
+		})
+	}
+	return out
+}
+
+// blockMap returns the attributes of a nested block. Blocks without any
+// attributes set are represented by nil in Terraform.
+func blockMap(item interface{}) map[string]interface{} {
+	if m, ok := item.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+func listOrNil(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+// expandStrings converts a Terraform list of strings into a string slice.
+// It returns nil for empty lists so that they are omitted from the policy file.
+func expandStrings(v interface{}) []string {
+	var out []string
+	for _, item := range listOrNil(v) {
+		s, _ := item.(string)
+		out = append(out, s)
+	}
+	return out
+}
+
+func marshalOptionalJSON(present bool, v any) (string, error) {
+	if !present {
+		return "", nil
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
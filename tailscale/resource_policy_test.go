@@ -0,0 +1,331 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"tailscale.com/client/tailscale/v2"
+
+	"github.com/tailscale/hujson"
+)
+
+const testPolicy = `
+	resource "tailscale_policy" "test_policy" {
+		grants {
+			src = ["group:example"]
+			dst = ["tag:example"]
+			ip  = ["tcp:443"]
+		}
+
+		groups {
+			name    = "group:example"
+			members = ["user1@example.com", "user2@example.com"]
+		}
+
+		tag_owners {
+			tag    = "tag:example"
+			owners = ["group:example"]
+		}
+
+		hosts = {
+			example-host-1 = "100.100.100.100"
+		}
+
+		auto_approvers {
+			routes {
+				cidr      = "10.0.0.0/24"
+				approvers = ["tag:example"]
+			}
+		}
+
+		ssh {
+			action       = "check"
+			src          = ["group:example"]
+			dst          = ["tag:example"]
+			users        = ["autogroup:nonroot"]
+			check_period = "12h"
+		}
+
+		tests {
+			src    = "user1@example.com"
+			accept = ["tag:example:443"]
+			deny   = ["example-host-1:22"]
+		}
+	}`
+
+func TestProvider_TailscalePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte("{}")
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			testResourceCreated("tailscale_policy.test_policy", testPolicy),
+			testResourceDestroyed("tailscale_policy.test_policy", testPolicy),
+		},
+	})
+}
+
+// TestProvider_TailscalePolicyNoDiff checks that a policy file matching the
+// configured blocks does not result in a diff, regardless of its formatting.
+func TestProvider_TailscalePolicyNoDiff(t *testing.T) {
+	const config = `
+		resource "tailscale_policy" "test_policy" {
+			grants {
+				src = ["*"]
+				dst = ["*"]
+				ip  = ["*"]
+			}
+
+			groups {
+				name    = "group:b"
+				members = ["b@example.com"]
+			}
+
+			groups {
+				name    = "group:a"
+				members = ["a@example.com"]
+			}
+		}`
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{
+				// Comments and formatting are ignored.
+				"groups": {"group:a": ["a@example.com"], "group:b": ["b@example.com"]},
+				"grants": [{"src": ["*"], "dst": ["*"], "ip": ["*"]}],
+			}`)
+		},
+		Steps: []resource.TestStep{
+			{ResourceName: "tailscale_policy.test_policy", Config: config},
+		},
+	})
+}
+
+func TestExpandPolicy(t *testing.T) {
+	t.Parallel()
+
+	d := schema.TestResourceDataRaw(t, resourcePolicy().Schema, map[string]interface{}{
+		"grants": []interface{}{
+			map[string]interface{}{
+				"src": []interface{}{"group:example"},
+				"dst": []interface{}{"tag:example"},
+				"ip":  []interface{}{"tcp:443"},
+				"app": `{"tailscale.com/cap/example":[{"role":"admin"}]}`,
+			},
+		},
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":    "group:example",
+				"members": []interface{}{"user1@example.com"},
+			},
+		},
+		"tag_owners": []interface{}{
+			map[string]interface{}{
+				"tag":    "tag:example",
+				"owners": []interface{}{"group:example"},
+			},
+		},
+		"hosts": map[string]interface{}{
+			"example-host-1": "100.100.100.100",
+		},
+		"auto_approvers": []interface{}{
+			map[string]interface{}{
+				"exit_node": []interface{}{"tag:example"},
+			},
+		},
+		"ssh": []interface{}{
+			map[string]interface{}{
+				"action":       "check",
+				"src":          []interface{}{"group:example"},
+				"dst":          []interface{}{"tag:example"},
+				"users":        []interface{}{"root"},
+				"check_period": "12h",
+			},
+		},
+		"tests": []interface{}{
+			map[string]interface{}{
+				"src":    "user1@example.com",
+				"accept": []interface{}{"tag:example:443"},
+			},
+		},
+	})
+
+	policy, err := expandPolicy(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	standardized, err := hujson.Standardize([]byte(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got tailscale.ACL
+	if err := json.Unmarshal(standardized, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	var twelveHours tailscale.SSHCheckPeriod
+	if err := twelveHours.UnmarshalText([]byte("12h")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := tailscale.ACL{
+		Grants: []tailscale.Grant{{
+			Source:      []string{"group:example"},
+			Destination: []string{"tag:example"},
+			IP:          []string{"tcp:443"},
+			App: map[string][]map[string]any{
+				"tailscale.com/cap/example": {{"role": "admin"}},
+			},
+		}},
+		Groups:        map[string][]string{"group:example": {"user1@example.com"}},
+		TagOwners:     map[string][]string{"tag:example": {"group:example"}},
+		Hosts:         map[string]string{"example-host-1": "100.100.100.100"},
+		AutoApprovers: &tailscale.ACLAutoApprovers{ExitNode: []string{"tag:example"}},
+		SSH: []tailscale.ACLSSH{{
+			Action:      "check",
+			Source:      []string{"group:example"},
+			Destination: []string{"tag:example"},
+			Users:       []string{"root"},
+			CheckPeriod: twelveHours,
+		}},
+		Tests: []tailscale.ACLTest{{
+			Source: "user1@example.com",
+			Accept: []string{"tag:example:443"},
+		}},
+	}
+
+	if err := assertEqual(want, got, "wrong policy"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlattenPolicy(t *testing.T) {
+	t.Parallel()
+
+	// Existing entries keep their order, new entries are sorted.
+	d := schema.TestResourceDataRaw(t, resourcePolicy().Schema, map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{"name": "group:zzz"},
+			map[string]interface{}{"name": "group:removed"},
+		},
+	})
+
+	props, err := flattenPolicy(d, &tailscale.ACL{
+		ACLs: []tailscale.ACLEntry{{
+			Users: []string{"*"},
+			Ports: []string{"*:*"},
+		}},
+		Groups: map[string][]string{
+			"group:bbb": {"b@example.com"},
+			"group:aaa": {"a@example.com"},
+			"group:zzz": {"z@example.com"},
+		},
+		Tests: []tailscale.ACLTest{{
+			User:  "a@example.com",
+			Allow: []string{"100.100.100.100:22"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantGroups := []map[string]any{
+		{"name": "group:zzz", "members": []string{"z@example.com"}},
+		{"name": "group:aaa", "members": []string{"a@example.com"}},
+		{"name": "group:bbb", "members": []string{"b@example.com"}},
+	}
+	if err := assertEqual(wantGroups, props["groups"], "wrong groups"); err != nil {
+		t.Error(err)
+	}
+
+	wantACLs := []map[string]any{{
+		"action":      "accept",
+		"src":         []string{"*"},
+		"dst":         []string{"*:*"},
+		"proto":       "",
+		"src_posture": []string(nil),
+	}}
+	if err := assertEqual(wantACLs, props["acls"], "wrong acls"); err != nil {
+		t.Error(err)
+	}
+
+	wantTests := []map[string]any{{
+		"src":    "a@example.com",
+		"accept": []string{"100.100.100.100:22"},
+		"deny":   []string(nil),
+	}}
+	if err := assertEqual(wantTests, props["tests"], "wrong tests"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResourcePolicyUpdate_ETag(t *testing.T) {
+	t.Parallel()
+
+	var ifMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+			if r.Header.Get("If-Match") == `"stale"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+		}
+		w.Header().Set("ETag", `"current"`)
+		w.Header().Set("Content-Type", "application/hujson")
+		w.Write([]byte(`{"hosts": {"example": "100.101.102.103"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, _ := url.Parse(server.URL)
+	client := &tailscale.Client{BaseURL: baseURL, APIKey: "not-a-real-key", Tailnet: "example.com"}
+
+	for _, tc := range []struct {
+		name      string
+		etag      string
+		overwrite bool
+		wantMatch string
+		wantError bool
+	}{
+		{name: "current", etag: `"current"`, wantMatch: `"current"`},
+		{name: "stale", etag: `"stale"`, wantMatch: `"stale"`, wantError: true},
+		{name: "overwrite", etag: `"stale"`, overwrite: true, wantMatch: ""},
+	} {
+		ifMatch = nil
+		d := resourcePolicy().Data(&terraform.InstanceState{
+			ID: "policy",
+			Attributes: map[string]string{
+				"etag":                         tc.etag,
+				"overwrite_concurrent_changes": strconv.FormatBool(tc.overwrite),
+			},
+		})
+
+		diags := resourcePolicyUpdate(context.Background(), d, client)
+		if diags.HasError() != tc.wantError {
+			t.Errorf("%s: unexpected diagnostics: %v", tc.name, diags)
+		}
+		if len(ifMatch) != 1 || ifMatch[0] != tc.wantMatch {
+			t.Errorf("%s: expected If-Match %q, got %q", tc.name, tc.wantMatch, ifMatch)
+		}
+	}
+}
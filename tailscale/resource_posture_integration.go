@@ -59,10 +59,25 @@ func resourcePostureIntegration() *schema.Resource {
 				Optional:    true,
 			},
 			"client_secret": {
-				Type:        schema.TypeString,
-				Description: "The secret (auth key, token, etc.) used to authenticate with the provider.",
-				Required:    true,
-				Sensitive:   true,
+				Type:         schema.TypeString,
+				Description:  "The secret (auth key, token, etc.) used to authenticate with the provider. Stored in the state, use `client_secret_wo` instead to keep it out of the state. Exactly one of `client_secret` or `client_secret_wo` must be set.",
+				Optional:     true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"client_secret", "client_secret_wo"},
+			},
+			"client_secret_wo": {
+				Type:         schema.TypeString,
+				Description:  "The write-only variant of `client_secret`, which is sent to the API but never stored in the state. Requires Terraform 1.11 or later. Changing only `client_secret_wo` does not update the resource, change `client_secret_wo_version` to send the new value.",
+				Optional:     true,
+				WriteOnly:    true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"client_secret", "client_secret_wo"},
+			},
+			"client_secret_wo_version": {
+				Type:         schema.TypeInt,
+				Description:  "The version of `client_secret_wo`. Change it to send a new value of `client_secret_wo` to the API.",
+				Optional:     true,
+				RequiredWith: []string{"client_secret_wo"},
 			},
 		},
 	}
@@ -105,7 +120,7 @@ func resourcePostureIntegrationCreate(ctx context.Context, d *schema.ResourceDat
 			CloudID:      d.Get("cloud_id").(string),
 			ClientID:     d.Get("client_id").(string),
 			TenantID:     d.Get("tenant_id").(string),
-			ClientSecret: d.Get("client_secret").(string),
+			ClientSecret: secretString(d, "client_secret"),
 		},
 	)
 	if err != nil {
@@ -126,7 +141,7 @@ func resourcePostureIntegrationUpdate(ctx context.Context, d *schema.ResourceDat
 			CloudID:      d.Get("cloud_id").(string),
 			ClientID:     d.Get("client_id").(string),
 			TenantID:     d.Get("tenant_id").(string),
-			ClientSecret: tailscale.PointerTo(d.Get("client_secret").(string)),
+			ClientSecret: tailscale.PointerTo(secretString(d, "client_secret")),
 		})
 	if err != nil {
 		return diagnosticsError(err, "Failed to update posture integration with id %q", d.Id())
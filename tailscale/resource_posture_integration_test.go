@@ -5,9 +5,12 @@ package tailscale
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -67,8 +70,8 @@ func TestAccTailscalePostureIntegration(t *testing.T) {
 	}
 
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories(t),
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProviderFactories(t),
 		CheckDestroy: checkResourceDestroyed(resourceName, func(client *tailscale.Client, rs *terraform.ResourceState) error {
 			_, err := client.DevicePosture().GetIntegration(context.Background(), rs.Primary.ID)
 			if err == nil {
@@ -137,3 +140,33 @@ func TestAccTailscalePostureIntegration(t *testing.T) {
 		},
 	})
 }
+
+func TestPostureIntegrationClientSecretWriteOnly(t *testing.T) {
+	client, testServer := NewTestHarness(t)
+	testServer.ResponseCode = http.StatusOK
+	testServer.ResponseBody = tailscale.PostureIntegration{
+		ID:       "test-integration",
+		Provider: tailscale.PostureIntegrationProviderFalcon,
+		ClientID: "clientid1",
+	}
+
+	// Write-only arguments are only ever in the configuration.
+	d := resourcePostureIntegration().Data(&terraform.InstanceState{
+		Attributes: map[string]string{"posture_provider": "falcon", "client_id": "clientid1"},
+		RawConfig:  cty.ObjectVal(map[string]cty.Value{"client_secret_wo": cty.StringVal("test-secret1")}),
+	})
+	if diags := resourcePostureIntegrationCreate(context.Background(), d, client); diags.HasError() {
+		t.Fatal(diagnosticsAsError(diags))
+	}
+
+	var body tailscale.CreatePostureIntegrationRequest
+	if err := json.Unmarshal(testServer.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ClientSecret != "test-secret1" {
+		t.Errorf("expected the write-only client secret to be sent, got %q", body.ClientSecret)
+	}
+	if secret := d.Get("client_secret").(string); secret != "" {
+		t.Errorf("expected the client secret not to be stored, got %q", secret)
+	}
+}
@@ -45,6 +45,7 @@ func resourceTailnetKey() *schema.Resource {
 					Type: schema.TypeString,
 				},
 				Optional:    true,
+				Computed:    true,
 				Description: "List of tags to apply to the machines authenticated by the key.",
 				ForceNew:    true,
 			},
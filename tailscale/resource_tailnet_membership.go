@@ -88,14 +88,13 @@ func resourceTailnetMembership() *schema.Resource {
 }
 
 // membershipResolve lists user invites and users for the tailnet and finds the membership by login_name (email).
-// It triggers client init by calling Users().List first. Returns nil if not found.
+// Returns nil if not found.
 func membershipResolve(ctx context.Context, client *tailscale.Client, loginName string) (*membershipResolveResult, diag.Diagnostics) {
 	normalized := strings.TrimSpace(strings.ToLower(loginName))
 	if normalized == "" {
 		return nil, diag.Errorf("login_name is empty")
 	}
 
-	// Trigger client init (required for OAuth so HTTP has auth transport).
 	users, err := client.Users().List(ctx, nil, nil)
 	if err != nil {
 		return nil, diagnosticsError(err, "Failed to list users for membership resolve")
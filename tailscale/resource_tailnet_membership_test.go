@@ -24,7 +24,7 @@ resource "tailscale_tailnet_membership" "alice" {
 func TestResourceTailnetMembership_Create_EnsureMembershipCreatesInvite(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -65,7 +65,7 @@ func TestResourceTailnetMembership_Create_EnsureMembershipCreatesInvite(t *testi
 func TestResourceTailnetMembership_Create_IdempotentWhenUserExists(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -104,7 +104,7 @@ func TestResourceTailnetMembership_Create_IdempotentWhenUserExists(t *testing.T)
 func TestResourceTailnetMembership_Read_StatePendingWhenInviteExists(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -131,7 +131,7 @@ func TestResourceTailnetMembership_Read_StatePendingWhenInviteExists(t *testing.
 func TestResourceTailnetMembership_Read_StateActiveWhenUserExists(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -162,7 +162,7 @@ func TestResourceTailnetMembership_Read_StateActiveWhenUserExists(t *testing.T)
 func TestResourceTailnetMembership_Delete_PendingCancelsInvite(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -195,7 +195,7 @@ func TestResourceTailnetMembership_Delete_PendingCancelsInvite(t *testing.T) {
 func TestResourceTailnetMembership_Delete_WhenUserExistsRemovesUser(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -229,7 +229,7 @@ func TestResourceTailnetMembership_Delete_WhenUserExistsRemovesUser(t *testing.T
 func TestResourceTailnetMembership_Delete_IdempotentWhenAlreadyRemoved(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -251,7 +251,7 @@ func TestResourceTailnetMembership_Delete_IdempotentWhenAlreadyRemoved(t *testin
 func TestResourceTailnetMembership_Update_SuspendAndRestore(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -297,7 +297,7 @@ resource "tailscale_tailnet_membership" "alice" {
 func TestResourceTailnetMembership_Delete_DowngradeOnDestroy(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -343,7 +343,7 @@ resource "tailscale_tailnet_membership" "alice" {
 func TestResourceTailnetMembership_Import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		IsUnitTest: true,
-		ProviderFactories: testProviderFactories(t),
+		ProtoV5ProviderFactories: testProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -0,0 +1,159 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// tagPolicy holds the provider settings applying to the tags written by
+// resources: the tags merged into the tags of every resource, and the
+// patterns every tag must match.
+type tagPolicy struct {
+	allowedPatterns []string
+	defaultTags     []string
+}
+
+// taggedResources are the resources whose `tags` are subject to the tag
+// policy of the provider.
+var taggedResources = map[string]bool{
+	"tailscale_device_tags":        true,
+	"tailscale_federated_identity": true,
+	"tailscale_oauth_client":       true,
+	"tailscale_tailnet_key":        true,
+}
+
+// tagPolicyFor returns the tag policy of the given client, or nil if the
+// provider sets neither allowed tag patterns nor default tags.
+func tagPolicyFor(client *tailscale.Client) *tagPolicy {
+	return providerStateFor(client).tagPolicy
+}
+
+// newTagPolicy returns the tag policy for the given patterns and default
+// tags, or an error if a pattern is malformed or a default tag does not
+// match the patterns.
+func newTagPolicy(allowedPatterns, defaultTags []string) (*tagPolicy, error) {
+	for _, pattern := range allowedPatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("pattern %q is invalid - %s", pattern, err)
+		}
+	}
+	policy := &tagPolicy{allowedPatterns: allowedPatterns, defaultTags: defaultTags}
+	if err := policy.check(defaultTags); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// check returns an error listing the tags that match none of the allowed
+// patterns. All tags are allowed if there are no patterns.
+func (p *tagPolicy) check(tags []string) error {
+	if len(p.allowedPatterns) == 0 {
+		return nil
+	}
+
+	var denied []string
+	for _, tag := range tags {
+		if !slices.ContainsFunc(p.allowedPatterns, func(pattern string) bool {
+			// Patterns are validated by newTagPolicy.
+			ok, _ := path.Match(pattern, tag)
+			return ok
+		}) {
+			denied = append(denied, fmt.Sprintf("%q", tag))
+		}
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf("tags %s do not match any of the allowed_tag_patterns of the provider (%s)", strings.Join(denied, ", "), strings.Join(p.allowedPatterns, ", "))
+	}
+	return nil
+}
+
+// merge returns the given tags with the default tags added, sorted, or an
+// error if any of them does not match the allowed patterns.
+func (p *tagPolicy) merge(tags []string) ([]string, error) {
+	merged := slices.Concat(p.defaultTags, tags)
+	slices.Sort(merged)
+	merged = slices.Compact(merged)
+	if err := p.check(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// withTagPolicy applies the tag policy of the provider to the computed
+// `tags` of a resource. The planned tags are the configured tags merged with
+// the default tags of the provider, and the plan fails if any of them does
+// not match the allowed tag patterns.
+func withTagPolicy(r *schema.Resource) {
+	r.Schema["tags"].Description += " The `default_tags` of the provider are added to these tags, and all tags must match the `allowed_tag_patterns` of the provider."
+
+	customizeDiff := r.CustomizeDiff
+	r.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if err := tagPolicyDiff(d, tagPolicyFor(m.(*tailscale.Client))); err != nil {
+			return err
+		}
+		if customizeDiff == nil {
+			return nil
+		}
+		return customizeDiff(ctx, d, m)
+	}
+}
+
+// tagPolicyDiff plans the tags of a resource from its configuration and the
+// given tag policy, which may be nil. As the tags are computed, they are
+// planned even without a tag policy, so that removing them from the
+// configuration removes them from the resource.
+func tagPolicyDiff(d *schema.ResourceDiff, policy *tagPolicy) error {
+	if policy == nil {
+		policy = &tagPolicy{}
+	}
+
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	configured := config.GetAttr("tags")
+	if !configured.IsWhollyKnown() {
+		// The tags are merged and checked once they are known.
+		if len(policy.defaultTags) > 0 {
+			return d.SetNewComputed("tags")
+		}
+		return nil
+	}
+
+	var configuredTags []string
+	if !configured.IsNull() {
+		for it := configured.ElementIterator(); it.Next(); {
+			if _, tag := it.Element(); !tag.IsNull() {
+				configuredTags = append(configuredTags, tag.AsString())
+			}
+		}
+	}
+	tags, err := policy.merge(configuredTags)
+	if err != nil {
+		return err
+	}
+
+	var planned []string
+	for _, tag := range d.Get("tags").(*schema.Set).List() {
+		planned = append(planned, tag.(string))
+	}
+	slices.Sort(planned)
+	if slices.Equal(tags, planned) {
+		return nil
+	}
+	newTags := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		newTags[i] = tag
+	}
+	return d.SetNew("tags", newTags)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider_TagPolicy(t *testing.T) {
+	mock, baseURL := newMockHeadscale(t)
+	resources := `
+		resource "tailscale_tailnet_key" "router" {
+			user_id = "1"
+			tags    = ["tag:team-router"]
+		}
+
+		resource "tailscale_device_tags" "router" {
+			device_id = "7"
+		}`
+
+	checkMock := func(wantKeyTags, wantNodeTags []string) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			mock.mu.Lock()
+			defer mock.mu.Unlock()
+			keyTags := slices.Sorted(slices.Values(mock.keys[len(mock.keys)-1].ACLTags))
+			if !slices.Equal(keyTags, wantKeyTags) {
+				return fmt.Errorf("expected key tags %v, got %v", wantKeyTags, keyTags)
+			}
+			if nodeTags := mock.nodes["7"].ForcedTags; !slices.Equal(nodeTags, wantNodeTags) {
+				return fmt.Errorf("expected node tags %v, got %v", wantNodeTags, nodeTags)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "tailscale" {
+						backend              = "headscale"
+						base_url             = %q
+						api_key              = "hskey-api-test"
+						allowed_tag_patterns = ["tag:team-*"]
+						default_tags         = ["tag:team-base"]
+					}`, baseURL) + resources,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("tailscale_tailnet_key.router", "tags.#", "2"),
+					resource.TestCheckTypeSetElemAttr("tailscale_tailnet_key.router", "tags.*", "tag:team-base"),
+					resource.TestCheckTypeSetElemAttr("tailscale_tailnet_key.router", "tags.*", "tag:team-router"),
+					resource.TestCheckResourceAttr("tailscale_device_tags.router", "tags.#", "1"),
+					resource.TestCheckTypeSetElemAttr("tailscale_device_tags.router", "tags.*", "tag:team-base"),
+					checkMock([]string{"tag:team-base", "tag:team-router"}, []string{"tag:team-base"}),
+				),
+			},
+			{
+				// Without default tags, the device tags must be configured.
+				Config:      testHeadscaleProviderConfig(baseURL) + resources,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`"tags" is required unless the provider sets default_tags`),
+			},
+			{
+				// Without default tags, only the configured tags are planned.
+				Config: testHeadscaleProviderConfig(baseURL) + strings.Replace(resources, `device_id = "7"`, `device_id = "7"
+					tags      = []`, 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("tailscale_tailnet_key.router", "tags.#", "1"),
+					resource.TestCheckResourceAttr("tailscale_device_tags.router", "tags.#", "0"),
+					checkMock([]string{"tag:team-router"}, nil),
+				),
+			},
+		},
+	})
+}
+
+func TestProvider_TagPolicyViolations(t *testing.T) {
+	providerConfig := func(settings string) string {
+		return `
+			provider "tailscale" {
+				api_key  = "tskey-api-test"
+				base_url = "http://localhost:1"
+				` + settings + `
+			}
+
+			resource "tailscale_oauth_client" "test" {
+				scopes = ["auth_keys"]
+				tags   = ["tag:team-ci", "tag:other"]
+			}`
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV5ProviderFactories: testConfiguredProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig(`allowed_tag_patterns = ["tag:team-*"]`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`tags "tag:other" do not match any of the\s+allowed_tag_patterns of the provider \(tag:team-\*\)`),
+			},
+			{
+				Config:      providerConfig(`allowed_tag_patterns = ["tag:team-*"]` + "\n" + `default_tags = ["tag:base"]`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`tailscale provider tag settings are invalid - tags "tag:base" do not\s+match`),
+			},
+			{
+				Config:      providerConfig(`allowed_tag_patterns = ["tag:["]`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`pattern "tag:\[" is invalid`),
+			},
+		},
+	})
+}
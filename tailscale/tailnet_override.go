@@ -0,0 +1,174 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"tailscale.com/client/tailscale/v2"
+)
+
+// tailnetPool is a set of clients sharing the credentials and HTTP client of
+// the provider, one for each tailnet. It does not reference the provider
+// client, so that the state of the provider is removed with its client.
+type tailnetPool struct {
+	mu      sync.Mutex
+	clients map[string]*tailscale.Client
+}
+
+// tailnetClient returns the client for the given tailnet, which shares the
+// credentials, HTTP client and settings of the given provider client. It
+// returns the provider client itself if tailnet is empty or the provider
+// tailnet.
+func tailnetClient(m interface{}, tailnet string) *tailscale.Client {
+	base := m.(*tailscale.Client)
+	if tailnet == "" || tailnet == base.Tailnet {
+		return base
+	}
+
+	return providerStateFor(base).tailnets.client(base, tailnet)
+}
+
+func (p *tailnetPool) client(base *tailscale.Client, tailnet string) *tailscale.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[tailnet]; ok {
+		return client
+	}
+
+	// Accessing a resource initializes the provider client, which wraps its
+	// HTTP client with the OAuth or identity federation authentication if
+	// configured. Reusing the initialized HTTP client shares the access token
+	// between all tailnets.
+	_ = base.Devices()
+	client := &tailscale.Client{
+		BaseURL:   base.BaseURL,
+		UserAgent: base.UserAgent,
+		APIKey:    base.APIKey,
+		HTTP:      base.HTTP,
+		Tailnet:   tailnet,
+	}
+	setProviderState(client, providerStateFor(base).forTailnet())
+
+	if p.clients == nil {
+		p.clients = make(map[string]*tailscale.Client)
+	}
+	p.clients[tailnet] = client
+	return client
+}
+
+// withTailnet adds an optional `tailnet` argument to a resource or data
+// source, which overrides the provider tailnet. The CRUD functions of the
+// resource are called with the client for that tailnet.
+//
+// If qualifyID is set, the IDs of resources in a tailnet other than the
+// provider tailnet are prefixed with the tailnet, as in `tailnet:id`. The
+// CRUD functions of the resource only ever see the unqualified ID. Resources
+// whose IDs already carry the tailnet, such as tailscale_tailnet_membership,
+// must not set qualifyID.
+func withTailnet(r *schema.Resource, isDataSource, qualifyID bool) *schema.Resource {
+	description := "The tailnet to manage this resource in. Defaults to the provider tailnet."
+	if isDataSource {
+		description = "The tailnet to read from. Defaults to the provider tailnet."
+	}
+	r.Schema["tailnet"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    !isDataSource,
+		Description: description,
+	}
+
+	r.CreateContext = withTailnetClient(r.CreateContext, qualifyID)
+	r.ReadContext = withTailnetClient(r.ReadContext, qualifyID)
+	r.UpdateContext = withTailnetClient(r.UpdateContext, qualifyID)
+	r.DeleteContext = withTailnetClient(r.DeleteContext, qualifyID)
+
+	if customizeDiff := r.CustomizeDiff; customizeDiff != nil {
+		r.CustomizeDiff = func(ctx context.Context, rd *schema.ResourceDiff, m interface{}) error {
+			return customizeDiff(ctx, rd, tailnetClient(m, rd.Get("tailnet").(string)))
+		}
+	}
+
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		importState := r.Importer.StateContext
+		r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+			tailnet, id, ok := splitTailnetID(d.Id())
+			if !ok || tailnet == m.(*tailscale.Client).Tailnet {
+				return importState(ctx, d, m)
+			}
+
+			if qualifyID {
+				d.SetId(id)
+			}
+			imported, err := importState(ctx, d, tailnetClient(m, tailnet))
+			if err != nil {
+				return nil, err
+			}
+			for _, rd := range imported {
+				if err := rd.Set("tailnet", tailnet); err != nil {
+					return nil, err
+				}
+				if qualifyID {
+					rd.SetId(tailnetID(tailnet, rd.Id()))
+				}
+			}
+			return imported, nil
+		}
+	}
+
+	return r
+}
+
+func withTailnetClient[F ~func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics](fn F, qualifyID bool) F {
+	if fn == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		tailnet := d.Get("tailnet").(string)
+		if tailnet == "" {
+			return fn(ctx, d, m)
+		}
+
+		if qualifyID && d.Id() != "" {
+			d.SetId(strings.TrimPrefix(d.Id(), tailnet+":"))
+		}
+		diags := fn(ctx, d, tailnetClient(m, tailnet))
+		if qualifyID && d.Id() != "" {
+			d.SetId(tailnetID(tailnet, d.Id()))
+		}
+		return diags
+	}
+}
+
+// tailnetID returns the ID of a resource in a tailnet other than the provider
+// tailnet.
+func tailnetID(tailnet, id string) string {
+	return tailnet + ":" + id
+}
+
+// splitTailnetID splits an import ID of the form `tailnet:id`. IDs that
+// contain a colon themselves, such as group and tag names or IPv6 routes,
+// are not split unless prefixed with a tailnet, as in
+// `example.com:group:engineering`.
+func splitTailnetID(id string) (tailnet, rest string, ok bool) {
+	tailnet, rest, ok = strings.Cut(id, ":")
+	if !ok || tailnet == "" || rest == "" {
+		return "", id, false
+	}
+	if _, err := netip.ParsePrefix(id); err == nil {
+		return "", id, false
+	}
+	switch tailnet {
+	case "group", "tag", "autogroup":
+		return "", id, false
+	}
+	return tailnet, rest, true
+}
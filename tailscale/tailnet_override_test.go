@@ -0,0 +1,82 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tailscale
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider_TailnetOverride(t *testing.T) {
+	const resourceName = "tailscale_acl_group.test_group"
+	const config = `
+		resource "tailscale_acl_group" "test_group" {
+			tailnet = "prod.example.com"
+			name    = "group:example"
+			members = ["user1@example.com"]
+		}`
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testServer.ResponseCode = http.StatusOK
+			testServer.ResponseBody = []byte(`{"groups": {"group:example": ["user1@example.com"]}}`)
+			testServer.ResponseQueueByPath = map[string][]interface{}{
+				"GET /api/v2/tailnet/prod.example.com/acl": {[]byte(`{}`)},
+			}
+		},
+		ProtoV5ProviderFactories: testProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", "prod.example.com:group:example"),
+					func(s *terraform.State) error {
+						if want := "/api/v2/tailnet/prod.example.com/acl"; testServer.Path != want {
+							return fmt.Errorf("expected request to %s, got %s", want, testServer.Path)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "prod.example.com:group:example",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestSplitTailnetID(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		id          string
+		wantTailnet string
+		wantID      string
+		wantOK      bool
+	}{
+		{"nodeidCNTRL", "", "nodeidCNTRL", false},
+		{"example.com:nodeidCNTRL", "example.com", "nodeidCNTRL", true},
+		{"group:engineering", "", "group:engineering", false},
+		{"example.com:group:engineering", "example.com", "group:engineering", true},
+		{"tag:web", "", "tag:web", false},
+		{"fd7a:115c:a1e0::/48", "", "fd7a:115c:a1e0::/48", false},
+		{"example.com:fd7a:115c:a1e0::/48", "example.com", "fd7a:115c:a1e0::/48", true},
+		{":id", "", ":id", false},
+	}
+
+	for _, tc := range tcs {
+		tailnet, id, ok := splitTailnetID(tc.id)
+		if tailnet != tc.wantTailnet || id != tc.wantID || ok != tc.wantOK {
+			t.Errorf("splitTailnetID(%q) = %q, %q, %t; want %q, %q, %t", tc.id, tailnet, id, ok, tc.wantTailnet, tc.wantID, tc.wantOK)
+		}
+	}
+}